@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"os"
+)
+
+const DEFAULT_MAX_REDIRECTS = 10
+
+// clientFor returns the shared Handler.Client unless the service has its own client settings,
+// in that case a client is built once per distinct settings and reused
+func (h Handler) clientFor(service config.Service) (HTTPClient, error) {
+	if !hasClientSettings(service) {
+		return h.Client, nil
+	}
+
+	key := clientKey(service)
+	if h.clients != nil {
+		if client, ok := h.clients.Load(key); ok {
+			return client.(HTTPClient), nil
+		}
+	}
+
+	client, err := newHTTPClient(service)
+	if err != nil {
+		return nil, err
+	}
+	if h.clients != nil {
+		actual, _ := h.clients.LoadOrStore(key, client)
+		return actual.(HTTPClient), nil
+	}
+	return client, nil
+}
+
+func hasClientSettings(service config.Service) bool {
+	return service.Timeout != "" || service.FollowRedirects != nil || service.MaxRedirects > 0 ||
+		service.TLS.InsecureSkipVerify || service.TLS.CA != "" || service.TLS.Cert != ""
+}
+
+func clientKey(service config.Service) string {
+	follow := service.FollowRedirects == nil || *service.FollowRedirects
+	return fmt.Sprintf("%s|%t|%d|%t|%s|%s|%s", timeout(service), follow, service.MaxRedirects,
+		service.TLS.InsecureSkipVerify, service.TLS.CA, service.TLS.Cert, service.TLS.Key)
+}
+
+func newHTTPClient(service config.Service) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(service)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Transport:     transport,
+		Timeout:       timeout(service),
+		CheckRedirect: redirectPolicy(service),
+	}, nil
+}
+
+func redirectPolicy(service config.Service) func(req *http.Request, via []*http.Request) error {
+	if service.FollowRedirects != nil && !*service.FollowRedirects {
+		return func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	max := service.MaxRedirects
+	if max <= 0 {
+		max = DEFAULT_MAX_REDIRECTS
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > max {
+			return fmt.Errorf("stopped after %d redirects", max)
+		}
+		return nil
+	}
+}
+
+// newTLSConfig builds the client TLS settings: custom CA bundle, client certificate and verification skip
+func newTLSConfig(service config.Service) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: service.TLS.InsecureSkipVerify}
+
+	if service.TLS.CA != "" {
+		pem, err := os.ReadFile(service.TLS.CA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", service.TLS.CA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if service.TLS.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(service.TLS.Cert, service.TLS.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
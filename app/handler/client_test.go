@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/pem"
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientForReusesClients(t *testing.T) {
+	defaultClient := &MockHTTPClient{}
+	handler := NewHandler(nil, defaultClient)
+
+	client, err := handler.clientFor(config.Service{Name: "plain"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultClient, client)
+
+	first, err := handler.clientFor(config.Service{Name: "first", Timeout: "3s"})
+	require.NoError(t, err)
+	second, err := handler.clientFor(config.Service{Name: "second", Timeout: "3s"})
+	require.NoError(t, err)
+	third, err := handler.clientFor(config.Service{Name: "third", Timeout: "5s"})
+	require.NoError(t, err)
+
+	assert.NotEqual(t, defaultClient, first)
+	assert.Same(t, first, second)
+	assert.NotSame(t, first, third)
+}
+
+func TestRedirectPolicy(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/final":
+			w.Write([]byte("OK"))
+		case "/loop":
+			http.Redirect(w, r, ts.URL+"/loop", http.StatusFound)
+		default:
+			http.Redirect(w, r, ts.URL+"/final", http.StatusMovedPermanently)
+		}
+	}))
+	defer ts.Close()
+
+	follow := false
+	response := NewHandler(nil, nil).checkHTTP(config.Service{
+		URL:             ts.URL + "/start",
+		FollowRedirects: &follow,
+		Response:        config.Response{Status: http.StatusMovedPermanently},
+	})
+	assert.Empty(t, response.Text)
+
+	response = NewHandler(nil, nil).checkHTTP(config.Service{
+		URL:          ts.URL + "/start",
+		MaxRedirects: 1,
+		Response:     config.Response{Status: http.StatusOK, Body: "OK"},
+	})
+	assert.Empty(t, response.Text)
+
+	response = NewHandler(nil, nil).checkHTTP(config.Service{
+		URL:          ts.URL + "/loop",
+		MaxRedirects: 3,
+		Response:     config.Response{Status: http.StatusOK},
+	})
+	assert.Equal(t, "Error making HTTP request", response.Text)
+	assert.ErrorContains(t, response.Err, "stopped after 3 redirects")
+}
+
+func TestClientTLSSettings(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer ts.Close()
+
+	service := config.Service{
+		URL:      ts.URL,
+		Response: config.Response{Status: http.StatusOK, Body: "OK"},
+	}
+
+	response := NewHandler(nil, &http.Client{}).checkHTTP(service)
+	assert.Equal(t, "Error making HTTP request", response.Text)
+
+	service.TLS.InsecureSkipVerify = true
+	response = NewHandler(nil, &http.Client{}).checkHTTP(service)
+	assert.Empty(t, response.Text)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0o600))
+
+	service.TLS = config.TLS{CA: caFile}
+	response = NewHandler(nil, &http.Client{}).checkHTTP(service)
+	assert.Empty(t, response.Text)
+
+	// the tls check type trusts the same bundle
+	response = checkTLS(config.Service{URL: ts.URL, TLS: config.TLS{CA: caFile}})
+	assert.Empty(t, response.Text)
+
+	service.TLS = config.TLS{CA: filepath.Join(t.TempDir(), "missing.pem")}
+	response = NewHandler(nil, &http.Client{}).checkHTTP(service)
+	assert.Equal(t, "Error creating HTTP client", response.Text)
+
+	service.TLS = config.TLS{Cert: "missing.crt", Key: "missing.key"}
+	_, err := newTLSConfig(service)
+	assert.ErrorContains(t, err, "failed to load client certificate")
+}
@@ -0,0 +1,354 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"micro-pinger/v2/app/metrics"
+	"micro-pinger/v2/app/sender"
+	config "micro-pinger/v2/app/service"
+	"micro-pinger/v2/app/store"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+const (
+	DEFAULT_RETRY_ATTEMPTS = 3
+	DEFAULT_RETRY_BACKOFF  = time.Second
+	DEFAULT_MAX_BACKOFF    = time.Minute
+)
+
+var errShutdown = errors.New("delivery stopped on shutdown")
+
+// Delivery sends alerts in background and retries failed deliveries with exponential backoff.
+// Alerts of the same service alert are sent in the order they were queued.
+type Delivery struct {
+	DeadLetter string // JSON lines file for alerts that could not be delivered, optional
+
+	mu       sync.Mutex
+	queues   map[string][]alertDelivery // pending alerts per "<service>_<alert>"
+	wg       sync.WaitGroup
+	fileLock sync.Mutex
+	done     chan struct{} // closed by Shutdown
+}
+
+// DeadLetter is an alert that was not delivered after all attempts
+type DeadLetter struct {
+	Time     time.Time      `json:"time"`
+	Service  string         `json:"service"`
+	Alert    string         `json:"alert"`
+	Type     string         `json:"type"`
+	Attempts int            `json:"attempts"`
+	Error    string         `json:"error"`
+	Message  sender.Message `json:"message"`
+}
+
+type alertDelivery struct {
+	key     string
+	alert   config.Alert
+	message sender.Message
+	sender  sender.Sender
+	store   store.Store // saves the slack thread of the alert, optional
+}
+
+func NewDelivery(deadLetter string) *Delivery {
+	return &Delivery{DeadLetter: deadLetter, queues: make(map[string][]alertDelivery), done: make(chan struct{})}
+}
+
+// Enqueue schedules the alert, it is sent after the pending alerts with the same key.
+// After Shutdown the alert goes to the dead letter file at once.
+func (d *Delivery) Enqueue(a alertDelivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped() {
+		d.fail(a, 0, errShutdown)
+		return
+	}
+	queue := d.queues[a.key]
+	d.queues[a.key] = append(queue, a)
+	if len(queue) == 0 {
+		d.wg.Add(1)
+		go d.drain(a.key)
+	}
+}
+
+// Wait blocks until every queued alert is delivered or dead-lettered
+func (d *Delivery) Wait() {
+	d.wg.Wait()
+}
+
+// Shutdown stops the retries and moves the pending alerts to the dead letter file, the alerts being
+// sent are finished. It waits for the queues to drain until ctx is done.
+func (d *Delivery) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.stopped() {
+		close(d.done)
+	}
+	d.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Delivery) stopped() bool {
+	select {
+	case <-d.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (d *Delivery) drain(key string) {
+	defer d.wg.Done()
+	for {
+		d.mu.Lock()
+		a := d.queues[key][0]
+		d.mu.Unlock()
+
+		if d.stopped() {
+			d.fail(a, 0, errShutdown)
+		} else {
+			d.send(a)
+		}
+
+		d.mu.Lock()
+		d.queues[key] = d.queues[key][1:]
+		if len(d.queues[key]) == 0 {
+			delete(d.queues, key)
+			d.mu.Unlock()
+			return
+		}
+		d.mu.Unlock()
+	}
+}
+
+func (d *Delivery) send(a alertDelivery) {
+	attempts, backoff, maxBackoff := retryPolicy(a.alert)
+	slack, threaded := a.sender.(*sender.Slack)
+	if threaded && slack.Message.ThreadTS == "" {
+		// the outage message may have been posted after this recovery was queued
+		thresholdMutex.Lock()
+		slack.Message.ThreadTS = SlackThread[a.key]
+		thresholdMutex.Unlock()
+	}
+
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		if err = a.sender.Send(); err == nil {
+			metrics.AlertSent(a.alert.Type)
+			if threaded {
+				a.saveSlackThread(slack.TS)
+			}
+			return
+		}
+		metrics.SenderError(a.alert.Type)
+		if attempt >= attempts || !sender.Retryable(err) {
+			break
+		}
+
+		wait := backoff
+		if retryAfter := sender.RetryAfter(err); retryAfter > wait {
+			wait = retryAfter
+		}
+		if wait > maxBackoff {
+			wait = maxBackoff
+		}
+		log.Printf("[WARN] [%s] failed to send alert %s, attempt %d of %d, retry in %s, %v",
+			a.message.ServiceName, a.alert.Name, attempt, attempts, wait, err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-d.done:
+			timer.Stop()
+			d.fail(a, attempt, fmt.Errorf("%w, %w", errShutdown, err))
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	d.fail(a, attempt, err)
+}
+
+// fail logs an alert that was not delivered and keeps it in the dead letter file
+func (d *Delivery) fail(a alertDelivery, attempts int, err error) {
+	log.Printf("[ERROR] [%s] failed to send alert %s after %d attempts, %v", a.message.ServiceName, a.alert.Name, attempts, err)
+	d.writeDeadLetter(DeadLetter{
+		Time:     time.Now(),
+		Service:  a.message.ServiceName,
+		Alert:    a.alert.Name,
+		Type:     a.alert.Type,
+		Attempts: attempts,
+		Error:    err.Error(),
+		Message:  a.message,
+	})
+}
+
+// saveSlackThread keeps the ts of the first message of an outage and forgets it once the recovery is posted
+func (a alertDelivery) saveSlackThread(ts string) {
+	thresholdMutex.Lock()
+	if a.message.Event.Failure() {
+		// a repeated alert of the same outage keeps the first thread
+		if ts == "" || SlackThread[a.key] != "" {
+			thresholdMutex.Unlock()
+			return
+		}
+		SlackThread[a.key] = ts
+	} else {
+		if SlackThread[a.key] == "" {
+			thresholdMutex.Unlock()
+			return
+		}
+		delete(SlackThread, a.key)
+	}
+	state := stamped(alertState(a.key))
+	thresholdMutex.Unlock()
+
+	saveStates(a.store, map[string]store.State{a.key: state})
+}
+
+func retryPolicy(alert config.Alert) (attempts int, backoff time.Duration, maxBackoff time.Duration) {
+	attempts, backoff, maxBackoff = alert.Retry.Attempts, DEFAULT_RETRY_BACKOFF, DEFAULT_MAX_BACKOFF
+	if attempts <= 0 {
+		attempts = DEFAULT_RETRY_ATTEMPTS
+	}
+	if d, err := time.ParseDuration(alert.Retry.Backoff); err == nil && d > 0 {
+		backoff = d
+	}
+	if d, err := time.ParseDuration(alert.Retry.MaxBackoff); err == nil && d > 0 {
+		maxBackoff = d
+	}
+	return attempts, backoff, maxBackoff
+}
+
+func (d *Delivery) writeDeadLetter(letter DeadLetter) {
+	if d.DeadLetter == "" {
+		return
+	}
+	data, err := json.Marshal(letter)
+	if err != nil {
+		log.Printf("[ERROR] failed to encode dead letter of %s, %v", letter.Service, err)
+		return
+	}
+
+	d.fileLock.Lock()
+	defer d.fileLock.Unlock()
+	// the file may hold service details, keep it private
+	file, err := os.OpenFile(d.DeadLetter, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Printf("[ERROR] failed to open dead letter file %s, %v", d.DeadLetter, err)
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		log.Printf("[ERROR] failed to write dead letter file %s, %v", d.DeadLetter, err)
+	}
+}
+
+// takeDeadLetters reads the dead letter file and empties it
+func (d *Delivery) takeDeadLetters() ([]DeadLetter, error) {
+	d.fileLock.Lock()
+	defer d.fileLock.Unlock()
+
+	data, err := os.ReadFile(d.DeadLetter)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	letters := []DeadLetter{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var letter DeadLetter
+		if err := decoder.Decode(&letter); err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+
+	return letters, os.Truncate(d.DeadLetter, 0)
+}
+
+// ReplayDeadLetters queues the dead-lettered alerts again with the current alert settings,
+// alerts of services or alerts removed from the config stay in the file.
+// POST /alerts/dead-letter/replay
+func (h Handler) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if h.Delivery == nil || h.Delivery.DeadLetter == "" {
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, JSON{"error": "dead letter log is not enabled"})
+		return
+	}
+	if r.Context().Value("config") != nil {
+		h.Services = r.Context().Value("config").(config.Config).Service
+	}
+
+	letters, err := h.Delivery.takeDeadLetters()
+	if err != nil {
+		log.Printf("[ERROR] failed to read dead letters, %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, JSON{"error": "failed to read dead letters"})
+		return
+	}
+
+	replayed, skipped := 0, 0
+	for _, letter := range letters {
+		alert, ok := h.findAlert(letter.Service, letter.Alert)
+		if !ok {
+			skipped++
+			h.Delivery.writeDeadLetter(letter)
+			continue
+		}
+
+		message := letter.Message
+		message.Alert = alert
+		message.Webhook = alert.Webhook
+		delivery, err := h.newAlertDelivery(letter.Service+"_"+alert.Name, alert, message)
+		if err != nil {
+			skipped++
+			h.Delivery.writeDeadLetter(letter)
+			continue
+		}
+		h.Delivery.Enqueue(delivery)
+		replayed++
+	}
+
+	log.Printf("[INFO] replayed %d dead letters, skipped %d", replayed, skipped)
+	render.JSON(w, r, JSON{"status": "ok", "replayed": replayed, "skipped": skipped})
+}
+
+func (h Handler) findAlert(serviceName string, alertName string) (config.Alert, bool) {
+	for _, service := range h.Services {
+		if service.Name != serviceName {
+			continue
+		}
+		for _, alert := range service.Alerts {
+			if alert.Name == alertName {
+				return alert, true
+			}
+		}
+	}
+	return config.Alert{}, false
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"micro-pinger/v2/app/sender"
+	config "micro-pinger/v2/app/service"
+	"micro-pinger/v2/app/store"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hookServer answers with the given status codes in turn and 200 afterwards
+type hookServer struct {
+	mu       sync.Mutex
+	statuses []int
+	requests int
+	header   http.Header
+}
+
+func (s *hookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	for name, values := range s.header {
+		w.Header()[name] = values
+	}
+	if len(s.statuses) > 0 {
+		w.WriteHeader(s.statuses[0])
+		s.statuses = s.statuses[1:]
+	}
+}
+
+func (s *hookServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func webhookService(name string, url string, retry config.Retry) config.Service {
+	return config.Service{
+		Name:     name,
+		URL:      "https://example.com",
+		Response: config.Response{Status: http.StatusOK},
+		Alerts: []config.Alert{
+			{Name: "SampleAlert", Type: "webhook", Webhook: url, Failure: 1, Success: 1, Retry: retry},
+		},
+	}
+}
+
+func TestDeliveryRetriesServerErrors(t *testing.T) {
+	hook := &hookServer{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway}}
+	mockServer := httptest.NewServer(hook)
+	defer mockServer.Close()
+
+	deadLetter := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	service := webhookService("SampleService_Retry", mockServer.URL, config.Retry{Attempts: 3, Backoff: "1ms"})
+	handler := NewHandler([]config.Service{service}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+	handler.Delivery = NewDelivery(deadLetter)
+
+	result, _ := handler.checkService(service)
+	assert.True(t, result.AlertQueued)
+	handler.Delivery.Wait()
+
+	assert.Equal(t, 3, hook.count())
+	_, err := os.Stat(deadLetter)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDeliveryHonorsRetryAfter(t *testing.T) {
+	hook := &hookServer{statuses: []int{http.StatusTooManyRequests}, header: http.Header{"Retry-After": {"1"}}}
+	mockServer := httptest.NewServer(hook)
+	defer mockServer.Close()
+
+	service := webhookService("SampleService_RetryAfter", mockServer.URL, config.Retry{Backoff: "1ms", MaxBackoff: "5s"})
+	handler := NewHandler([]config.Service{service}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+
+	start := time.Now()
+	handler.CheckService(service)
+	handler.Delivery.Wait()
+
+	assert.Equal(t, 2, hook.count())
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestDeliveryDeadLetterAndReplay(t *testing.T) {
+	hook := &hookServer{statuses: []int{http.StatusBadRequest}}
+	mockServer := httptest.NewServer(hook)
+	defer mockServer.Close()
+
+	deadLetter := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	service := webhookService("SampleService_DeadLetter", mockServer.URL, config.Retry{Backoff: "1ms"})
+	handler := NewHandler([]config.Service{service}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+	handler.Delivery = NewDelivery(deadLetter)
+
+	handler.CheckService(service)
+	handler.Delivery.Wait()
+	// a client error is not retried
+	assert.Equal(t, 1, hook.count())
+
+	data, err := os.ReadFile(deadLetter)
+	require.NoError(t, err)
+	var letter DeadLetter
+	require.NoError(t, json.Unmarshal(data, &letter))
+	assert.Equal(t, "SampleService_DeadLetter", letter.Service)
+	assert.Equal(t, "SampleAlert", letter.Alert)
+	assert.Equal(t, 1, letter.Attempts)
+	assert.Equal(t, "unexpected status code: 400", letter.Error)
+	assert.Equal(t, "Unexpected response status", letter.Message.Response.Text)
+
+	// an alert removed from the config stays in the file
+	orphan := letter
+	orphan.Alert = "RemovedAlert"
+	line, err := json.Marshal(orphan)
+	require.NoError(t, err)
+	file, err := os.OpenFile(deadLetter, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	file.Write(append(line, '\n'))
+	file.Close()
+
+	w := httptest.NewRecorder()
+	handler.ReplayDeadLetters(w, httptest.NewRequest(http.MethodPost, "/alerts/dead-letter/replay", nil))
+	handler.Delivery.Wait()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status": "ok", "replayed": 1, "skipped": 1}`, w.Body.String())
+	assert.Equal(t, 2, hook.count())
+
+	data, err = os.ReadFile(deadLetter)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\n"))
+	assert.Contains(t, string(data), `"alert":"RemovedAlert"`)
+}
+
+func TestDeliveryShutdown(t *testing.T) {
+	hook := &hookServer{statuses: []int{http.StatusServiceUnavailable}}
+	mockServer := httptest.NewServer(hook)
+	defer mockServer.Close()
+
+	deadLetter := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	service := webhookService("SampleService_Shutdown", mockServer.URL, config.Retry{Attempts: 3, Backoff: "1h"})
+	handler := NewHandler([]config.Service{service}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+	handler.Delivery = NewDelivery(deadLetter)
+
+	handler.CheckService(service)
+	assert.Eventually(t, func() bool { return hook.count() == 1 }, time.Second, time.Millisecond)
+
+	// the retry waiting for an hour is given up
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, handler.Delivery.Shutdown(ctx))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// alerts queued after shutdown are not sent
+	delivery, err := handler.newAlertDelivery(service.Name+"_SampleAlert", service.Alerts[0], sender.Message{ServiceName: service.Name})
+	require.NoError(t, err)
+	handler.Delivery.Enqueue(delivery)
+	assert.Equal(t, 1, hook.count())
+
+	data, err := os.ReadFile(deadLetter)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var letter DeadLetter
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &letter))
+	assert.Equal(t, 1, letter.Attempts)
+	assert.Equal(t, "delivery stopped on shutdown, unexpected status code: 503", letter.Error)
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &letter))
+	assert.Equal(t, 0, letter.Attempts)
+	assert.Equal(t, "delivery stopped on shutdown", letter.Error)
+}
+
+func TestDeliverySavesSlackThread(t *testing.T) {
+	var threads []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message sender.SlackMessage
+		json.NewDecoder(r.Body).Decode(&message)
+		threads = append(threads, message.ThreadTS)
+		w.Write([]byte(`{"ok": true, "ts": "1700000000.000100"}`))
+	}))
+	defer mockServer.Close()
+
+	service := config.Service{
+		Name:     "SampleService_SlackThread",
+		URL:      "https://example.com",
+		Response: config.Response{Status: http.StatusOK},
+		Alerts: []config.Alert{{
+			Name: "SampleAlert", Type: "slack", BotToken: "xoxb-token", To: "#alerts", APIURL: mockServer.URL,
+			Failure: 1, Success: 1, SendOnResolve: true,
+		}},
+	}
+	alertName := service.Name + "_SampleAlert"
+	st := store.NewMemory()
+	handler := NewHandler([]config.Service{service}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+	handler.Store = st
+	handler.checkService(service)
+	handler.Delivery.Wait()
+
+	states, err := st.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "1700000000.000100", states[alertName].SlackThread)
+
+	// simulate a restart in the middle of an outage
+	delete(SlackThread, alertName)
+	require.NoError(t, RestoreState(st))
+
+	handler.Client = &MockHTTPClient{StatusCode: http.StatusOK}
+	handler.checkService(service)
+	handler.Delivery.Wait()
+
+	assert.Equal(t, []string{"", "1700000000.000100"}, threads, "the recovery is posted in the thread of the outage")
+	states, err = st.Load()
+	require.NoError(t, err)
+	assert.Empty(t, states[alertName].SlackThread)
+}
+
+func TestReplayDeadLettersDisabled(t *testing.T) {
+	handler := NewHandler(nil, &MockHTTPClient{StatusCode: http.StatusOK})
+
+	w := httptest.NewRecorder()
+	handler.ReplayDeadLetters(w, httptest.NewRequest(http.MethodPost, "/alerts/dead-letter/replay", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	attempts, backoff, maxBackoff := retryPolicy(config.Alert{})
+	assert.Equal(t, DEFAULT_RETRY_ATTEMPTS, attempts)
+	assert.Equal(t, DEFAULT_RETRY_BACKOFF, backoff)
+	assert.Equal(t, DEFAULT_MAX_BACKOFF, maxBackoff)
+
+	attempts, backoff, maxBackoff = retryPolicy(config.Alert{Retry: config.Retry{Attempts: 5, Backoff: "2s", MaxBackoff: "30s"}})
+	assert.Equal(t, 5, attempts)
+	assert.Equal(t, 2*time.Second, backoff)
+	assert.Equal(t, 30*time.Second, maxBackoff)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"micro-pinger/v2/app/sender"
+	config "micro-pinger/v2/app/service"
+	"net"
+	"sort"
+	"strings"
+)
+
+// checkDNS resolves service.URL and compares the records with service.DNS.Values.
+// All expected values must be returned, with compare "contains" extra records are allowed.
+func checkDNS(service config.Service) sender.Response {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout(service))
+	defer cancel()
+
+	name := service.URL
+	record := strings.ToUpper(service.DNS.Record)
+	if record == "" {
+		record = "A"
+	}
+
+	values, err := lookup(ctx, newResolver(service.DNS.Resolver), record, name)
+	if err != nil {
+		return sender.Response{
+			Text: fmt.Sprintf("Error resolving %s record for %s", record, name),
+			Err:  err,
+		}
+	}
+
+	if len(values) == 0 {
+		return sender.Response{
+			Text: fmt.Sprintf("No %s records for %s", record, name),
+		}
+	}
+
+	if len(service.DNS.Values) == 0 {
+		return sender.Response{}
+	}
+
+	actual := make(map[string]bool, len(values))
+	for _, value := range values {
+		actual[value] = true
+	}
+	matched := 0
+	for _, expected := range service.DNS.Values {
+		if actual[normalizeRecord(record, expected)] {
+			matched++
+		}
+	}
+
+	if matched != len(service.DNS.Values) || (service.Response.Compare != "contains" && len(values) != len(service.DNS.Values)) {
+		return sender.Response{
+			Text: fmt.Sprintf("Unexpected %s records for %s: %s", record, name, strings.Join(values, ", ")),
+		}
+	}
+
+	return sender.Response{}
+}
+
+func newResolver(address string) *net.Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := net.Dialer{}
+			return d.DialContext(ctx, network, address)
+		},
+	}
+}
+
+func lookup(ctx context.Context, resolver *net.Resolver, record, name string) ([]string, error) {
+	var values []string
+	switch record {
+	case "A", "AAAA":
+		network := "ip4"
+		if record == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			values = append(values, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, cname)
+	case "MX":
+		mxs, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			values = append(values, mx.Host)
+		}
+	case "TXT":
+		txts, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, txts...)
+	case "NS":
+		nss, err := resolver.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			values = append(values, ns.Host)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported record type %s", record)
+	}
+
+	for i, value := range values {
+		values[i] = normalizeRecord(record, value)
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+// normalizeRecord makes host names comparable: lower case without the trailing dot
+func normalizeRecord(record, value string) string {
+	switch record {
+	case "CNAME", "MX", "NS":
+		return strings.ToLower(strings.TrimSuffix(value, "."))
+	case "A", "AAAA":
+		if ip := net.ParseIP(value); ip != nil {
+			return ip.String()
+		}
+	}
+	return value
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	config "micro-pinger/v2/app/service"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// startDNSServer answers A, MX and TXT queries for example.test. from a static zone
+func startDNSServer(t *testing.T) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var p dnsmessage.Parser
+			header, err := p.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+			question, err := p.Question()
+			if err != nil {
+				continue
+			}
+
+			header.Response = true
+			header.Authoritative = true
+			header.RCode = dnsmessage.RCodeSuccess
+			if question.Name.String() != "example.test." {
+				header.RCode = dnsmessage.RCodeNameError
+			}
+
+			b := dnsmessage.NewBuilder(nil, header)
+			b.EnableCompression()
+			b.StartQuestions()
+			b.Question(question)
+			b.StartAnswers()
+			rh := dnsmessage.ResourceHeader{Name: question.Name, Type: question.Type, Class: dnsmessage.ClassINET, TTL: 60}
+			if header.RCode == dnsmessage.RCodeSuccess {
+				switch question.Type {
+				case dnsmessage.TypeA:
+					b.AResource(rh, dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}})
+					b.AResource(rh, dnsmessage.AResource{A: [4]byte{192, 0, 2, 2}})
+				case dnsmessage.TypeMX:
+					b.MXResource(rh, dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mail.example.test.")})
+				case dnsmessage.TypeTXT:
+					b.TXTResource(rh, dnsmessage.TXTResource{TXT: []string{"v=spf1 -all"}})
+				}
+			}
+			msg, err := b.Finish()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(msg, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestCheckDNS(t *testing.T) {
+	resolver := startDNSServer(t)
+
+	testCases := []struct {
+		name     string
+		record   string
+		values   []string
+		compare  string
+		host     string
+		expected string
+	}{
+		{name: "AnyA", record: "A", host: "example.test"},
+		{name: "ExactA", record: "a", values: []string{"192.0.2.2", "192.0.2.1"}, host: "example.test"},
+		{name: "ContainsA", record: "A", values: []string{"192.0.2.1"}, compare: "contains", host: "example.test"},
+		{
+			name:     "ExactAWithExtraRecord",
+			record:   "A",
+			values:   []string{"192.0.2.1"},
+			host:     "example.test",
+			expected: "Unexpected A records for example.test: 192.0.2.1, 192.0.2.2",
+		},
+		{
+			name:     "HijackedA",
+			record:   "A",
+			values:   []string{"198.51.100.7"},
+			compare:  "contains",
+			host:     "example.test",
+			expected: "Unexpected A records for example.test: 192.0.2.1, 192.0.2.2",
+		},
+		{name: "MX", record: "MX", values: []string{"Mail.Example.Test."}, host: "example.test"},
+		{name: "TXT", record: "TXT", values: []string{"v=spf1 -all"}, host: "example.test"},
+		{name: "NotFound", record: "A", host: "missing.test", expected: "Error resolving A record for missing.test"},
+		{name: "UnsupportedRecord", record: "SRV", host: "example.test", expected: "Error resolving SRV record for example.test"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := checkDNS(config.Service{
+				Name:     tc.name,
+				Type:     "dns",
+				URL:      tc.host,
+				Timeout:  "2s",
+				DNS:      config.DNS{Resolver: resolver, Record: tc.record, Values: tc.values},
+				Response: config.Response{Compare: tc.compare},
+			})
+			assert.Equal(t, tc.expected, response.Text)
+		})
+	}
+}
+
+func TestNewResolverDefaultPort(t *testing.T) {
+	assert.Equal(t, net.DefaultResolver, newResolver(""))
+	assert.NotEqual(t, net.DefaultResolver, newResolver("127.0.0.1"))
+}
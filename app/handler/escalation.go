@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"log"
+	config "micro-pinger/v2/app/service"
+	"micro-pinger/v2/app/store"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// Acknowledged keeps the time an outage was acknowledged, keyed by "<service>_<alert>"
+var Acknowledged = make(map[string]time.Time)
+
+// escalated reports whether the alert is sent by the escalation steps of the service
+func escalated(service config.Service, alert config.Alert) bool {
+	for _, step := range service.Escalation {
+		if stepTargets(step, alert.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// escalationDue reports whether an escalated alert has to be sent now: the first time one of its steps
+// becomes active and then every repeat interval of the active steps, until the outage is acknowledged.
+// Must be called with thresholdMutex held.
+func escalationDue(service config.Service, alert config.Alert, alertName string, now time.Time) bool {
+	if !Acknowledged[alertName].IsZero() {
+		return false
+	}
+
+	downtime := now.Sub(DownSince[alertName])
+	active := false
+	var repeat time.Duration
+	for _, step := range service.Escalation {
+		if !stepTargets(step, alert.Name) || FailureThreshold[alertName] < step.Failures {
+			continue
+		}
+		if after, err := time.ParseDuration(step.After); err == nil && downtime < after {
+			continue
+		}
+		active = true
+		if r, err := time.ParseDuration(step.Repeat); err == nil && r > 0 && (repeat == 0 || r < repeat) {
+			repeat = r
+		}
+	}
+	if !active {
+		return false
+	}
+
+	if !notified(alertName) {
+		return true
+	}
+	return repeat > 0 && now.Sub(LastAlert[alertName]) >= repeat
+}
+
+func stepTargets(step config.EscalationStep, alertName string) bool {
+	for _, name := range step.Alerts {
+		if name == alertName {
+			return true
+		}
+	}
+	return false
+}
+
+// notified reports whether the alert was sent during the current outage
+func notified(alertName string) bool {
+	return !DownSince[alertName].IsZero() && !LastAlert[alertName].Before(DownSince[alertName])
+}
+
+// Acknowledge stops the escalation of the current outage of a service, recovery is still reported.
+// POST /services/{name}/ack
+func (h Handler) Acknowledge(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Value("config") != nil {
+		h.Services = r.Context().Value("config").(config.Config).Service
+	}
+
+	name := chi.URLParam(r, "name")
+	var service *config.Service
+	for i := range h.Services {
+		if h.Services[i].Name == name {
+			service = &h.Services[i]
+			break
+		}
+	}
+	if service == nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, JSON{"error": "service " + name + " not found"})
+		return
+	}
+
+	thresholdMutex.Lock()
+	states := make(map[string]store.State)
+	now := time.Now()
+	for _, alert := range service.Alerts {
+		alertName := service.Name + "_" + alert.Name
+		if DownSince[alertName].IsZero() {
+			continue
+		}
+		if Acknowledged[alertName].IsZero() {
+			Acknowledged[alertName] = now
+		}
+		states[alertName] = stamped(alertState(alertName))
+	}
+	thresholdMutex.Unlock()
+
+	if len(states) == 0 {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, JSON{"error": "service " + name + " is not down"})
+		return
+	}
+
+	saveStates(h.Store, states)
+	log.Printf("[INFO] [%s] outage acknowledged", name)
+	render.JSON(w, r, JSON{"status": "ok", "service": name})
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckServiceEscalation(t *testing.T) {
+	var mu sync.Mutex
+	var notifications []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		notifications = append(notifications, r.URL.Path)
+	}))
+	defer mockServer.Close()
+
+	payload := `{"event": {{json .Event}}}`
+	service := config.Service{
+		Name:     "SampleService_Escalation",
+		URL:      "https://example.com",
+		Response: config.Response{Status: http.StatusOK},
+		Alerts: []config.Alert{
+			{Name: "devops", Type: "webhook", Webhook: mockServer.URL + "/devops/down", Payload: payload, Failure: 1, Success: 1, SendOnResolve: true},
+			{Name: "manager", Type: "webhook", Webhook: mockServer.URL + "/manager/down", Payload: payload, Success: 1, SendOnResolve: true},
+		},
+		Escalation: []config.EscalationStep{
+			{Failures: 2, Alerts: []string{"devops"}, Repeat: "15m"},
+			{After: "30m", Alerts: []string{"manager"}},
+		},
+	}
+	devops, manager := service.Name+"_devops", service.Name+"_manager"
+
+	handler := NewHandler([]config.Service{service}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+	router := chi.NewRouter()
+	router.Post("/services/{name}/ack", handler.Acknowledge)
+	check := func() []string {
+		handler.CheckService(service)
+		handler.Delivery.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		sent := notifications
+		notifications = nil
+		return sent
+	}
+	// elapse moves the outage of the alert back in time
+	elapse := func(alertName string, d time.Duration) {
+		DownSince[alertName] = DownSince[alertName].Add(-d)
+		if !LastAlert[alertName].IsZero() {
+			LastAlert[alertName] = LastAlert[alertName].Add(-d)
+		}
+	}
+	ack := func(name string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/services/"+name+"/ack", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusConflict, ack(service.Name))
+	assert.Equal(t, http.StatusNotFound, ack("unknown"))
+
+	// the own failure threshold of an escalated alert is ignored
+	assert.Empty(t, check())
+	assert.Equal(t, []string{"/devops/down"}, check())
+	assert.Empty(t, check())
+
+	// repeat interval is over
+	elapse(devops, 16*time.Minute)
+	elapse(manager, 16*time.Minute)
+	assert.Equal(t, []string{"/devops/down"}, check())
+
+	// the outage lasts long enough for the second step
+	elapse(devops, 15*time.Minute)
+	elapse(manager, 15*time.Minute)
+	assert.ElementsMatch(t, []string{"/devops/down", "/manager/down"}, check())
+
+	assert.Equal(t, http.StatusOK, ack(service.Name))
+	assert.False(t, Acknowledged[devops].IsZero())
+	elapse(devops, 16*time.Minute)
+	elapse(manager, 16*time.Minute)
+	assert.Empty(t, check())
+
+	// both notified alerts hear about the recovery
+	handler.Client = &MockHTTPClient{StatusCode: http.StatusOK}
+	assert.ElementsMatch(t, []string{"/devops/down", "/manager/down"}, check())
+	assert.True(t, Acknowledged[devops].IsZero())
+}
+
+func TestCheckServiceEscalationRecoveryNotNotified(t *testing.T) {
+	var mu sync.Mutex
+	var notifications []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		notifications = append(notifications, r.URL.Path)
+	}))
+	defer mockServer.Close()
+
+	service := config.Service{
+		Name:     "SampleService_EscalationShort",
+		URL:      "https://example.com",
+		Response: config.Response{Status: http.StatusOK},
+		Alerts: []config.Alert{
+			{Name: "manager", Type: "webhook", Webhook: mockServer.URL + "/manager", Success: 1, SendOnResolve: true},
+		},
+		Escalation: []config.EscalationStep{{After: "30m", Alerts: []string{"manager"}}},
+	}
+
+	handler := NewHandler([]config.Service{service}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+	handler.CheckService(service)
+	handler.Client = &MockHTTPClient{StatusCode: http.StatusOK}
+	handler.CheckService(service)
+	handler.Delivery.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Empty(t, notifications)
+}
+
+func TestAcknowledgeFlappingOutage(t *testing.T) {
+	service := config.Service{
+		Name:     "SampleService_EscalationFlapping",
+		URL:      "https://example.com",
+		Response: config.Response{Status: http.StatusOK},
+		Alerts:   []config.Alert{{Name: "devops", Type: "webhook", Webhook: "http://127.0.0.1:1", Success: 3}},
+		Escalation: []config.EscalationStep{
+			{Failures: 5, Alerts: []string{"devops"}},
+		},
+	}
+	alertName := service.Name + "_devops"
+
+	handler := NewHandler([]config.Service{service}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+	router := chi.NewRouter()
+	router.Post("/services/{name}/ack", handler.Acknowledge)
+
+	handler.CheckService(service)
+	// a single passing check doesn't end the outage
+	handler.Client = &MockHTTPClient{StatusCode: http.StatusOK}
+	handler.CheckService(service)
+	assert.Equal(t, STATUS_UP, LastStatus[alertName])
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/services/"+service.Name+"/ack", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, Acknowledged[alertName].IsZero())
+}
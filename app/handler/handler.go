@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"micro-pinger/v2/app/metrics"
 	"micro-pinger/v2/app/sender"
 	config "micro-pinger/v2/app/service"
+	"micro-pinger/v2/app/store"
 	"net/http"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/go-chi/chi/v5"
 )
 
 type JSON map[string]interface{}
@@ -20,16 +25,28 @@ var (
 	thresholdMutex   sync.Mutex
 	FailureThreshold = make(map[string]int)
 	SuccessThreshold = make(map[string]int)
+	LastStatus       = make(map[string]string)
+	LastAlert        = make(map[string]time.Time)
+	DownSince        = make(map[string]time.Time)
+	LastNotified     = make(map[string]string) // state sent with the last alert, the previous state of the next one
+	SlackThread      = make(map[string]string) // ts of the slack outage message, the recovery is posted in its thread
 )
 
 const (
 	LIMIT_MAX_FAILURE = 10000
 	LIMIT_MAX_SUCCESS = 10000
+	DEFAULT_TIMEOUT   = 10 * time.Second
+
+	STATUS_UP   = "up"
+	STATUS_DOWN = "down"
 )
 
 type Handler struct {
 	Services []config.Service
 	Client   HTTPClient
+	Store    store.Store // optional, persists alert states
+	Delivery *Delivery   // sends alerts in background, alerts are sent in place if nil
+	clients  *sync.Map   // per-service clients built by clientFor
 }
 
 type HTTPClient interface {
@@ -37,9 +54,10 @@ type HTTPClient interface {
 }
 
 func NewHandler(services []config.Service, client HTTPClient) Handler {
-	return Handler{Services: services, Client: client}
+	return Handler{Services: services, Client: client, Delivery: NewDelivery(""), clients: &sync.Map{}}
 }
 
+// Check runs checks of all services in background, with ?wait=true it waits and returns the results
 func (h Handler) Check(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -47,13 +65,101 @@ func (h Handler) Check(w http.ResponseWriter, r *http.Request) {
 		h.Services = r.Context().Value("config").(config.Config).Service
 	}
 
+	if r.URL.Query().Get("wait") != "true" {
+		for _, service := range h.Services {
+			go h.CheckService(service)
+		}
+		json.NewEncoder(w).Encode(JSON{"status": "ok"})
+		return
+	}
+
+	results := make([]Result, len(h.Services))
+	var wg sync.WaitGroup
+	for i, service := range h.Services {
+		wg.Add(1)
+		go func(i int, service config.Service) {
+			defer wg.Done()
+			results[i], _ = h.checkService(service)
+		}(i, service)
+	}
+	wg.Wait()
+
+	writeResults(w, results)
+}
+
+// CheckOne runs the check of a single service and returns its result
+func (h Handler) CheckOne(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Context().Value("config") != nil {
+		h.Services = r.Context().Value("config").(config.Config).Service
+	}
+
+	name := chi.URLParam(r, "name")
 	for _, service := range h.Services {
-		go h.CheckService(service)
+		if service.Name == name {
+			result, _ := h.checkService(service)
+			writeResults(w, []Result{result})
+			return
+		}
 	}
-	json.NewEncoder(w).Encode(JSON{"status": "ok"})
+
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(JSON{"status": "error", "error": "service " + name + " not found"})
 }
 
 func (h Handler) CheckService(service config.Service) error {
+	_, err := h.checkService(service)
+	return err
+}
+
+func (h Handler) checkService(service config.Service) (Result, error) {
+	var response sender.Response
+	start := time.Now()
+	switch service.Type {
+	case "", "http", "json":
+		response = h.checkHTTP(service)
+	case "tcp":
+		response = checkTCP(service)
+	case "dns":
+		response = checkDNS(service)
+	case "tls":
+		response = checkTLS(service)
+	default:
+		response = sender.Response{
+			Text: "Unsupported check type '" + service.Type + "'",
+			Code: 500,
+			Err:  nil,
+		}
+	}
+	response.Latency = time.Since(start)
+
+	if response.Text == "" && service.Response.MaxLatency != "" {
+		maxLatency, err := time.ParseDuration(service.Response.MaxLatency)
+		if err == nil && response.Latency > maxLatency {
+			response.Text = fmt.Sprintf("Response time %s exceeds %s", response.Latency.Round(time.Millisecond), maxLatency)
+			response.Status = "Service is slow"
+			response.Event = sender.EVENT_DEGRADED
+		}
+	}
+
+	h.recordResult(service, response)
+	metrics.ObserveCheck(service.Name, response.Text == "", response.Latency)
+
+	deliveries, states, err := h.sendAlerts(service, response)
+	// saving and delivery with retries run outside thresholdMutex
+	saveStates(h.Store, states)
+	for _, delivery := range deliveries {
+		if h.Delivery != nil {
+			h.Delivery.Enqueue(delivery)
+		} else {
+			(&Delivery{}).send(delivery)
+		}
+	}
+	return newResult(service, response, len(deliveries) > 0), err
+}
+
+func (h Handler) checkHTTP(service config.Service) sender.Response {
 	req, err := http.NewRequest(service.Method, service.URL, strings.NewReader(service.Body))
 
 	if err != nil {
@@ -62,7 +168,7 @@ func (h Handler) CheckService(service config.Service) error {
 			Code: 500,
 			Err:  err,
 		}
-		return sendAlerts(service, errMsg)
+		return errMsg
 	}
 	defer req.Body.Close()
 	if service.Headers != nil {
@@ -71,14 +177,24 @@ func (h Handler) CheckService(service config.Service) error {
 		}
 	}
 
-	resp, err := h.Client.Do(req)
+	client, err := h.clientFor(service)
+	if err != nil {
+		errMsg := sender.Response{
+			Text: "Error creating HTTP client",
+			Code: 500,
+			Err:  err,
+		}
+		return errMsg
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		errMsg := sender.Response{
 			Text: "Error making HTTP request",
 			Code: 500,
 			Err:  err,
 		}
-		return sendAlerts(service, errMsg)
+		return errMsg
 	}
 	defer resp.Body.Close()
 
@@ -88,7 +204,14 @@ func (h Handler) CheckService(service config.Service) error {
 			Code: resp.StatusCode,
 			Err:  nil,
 		}
-		return sendAlerts(service, errMsg)
+		return errMsg
+	}
+
+	if len(service.Response.Headers) > 0 {
+		if errMsg := checkHeaders(service, resp.Header); errMsg.Text != "" {
+			errMsg.Code = resp.StatusCode
+			return errMsg
+		}
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
@@ -98,7 +221,22 @@ func (h Handler) CheckService(service config.Service) error {
 			Code: resp.StatusCode,
 			Err:  err,
 		}
-		return sendAlerts(service, errMsg)
+		return errMsg
+	}
+
+	if len(service.Response.JSON) > 0 {
+		if errMsg := checkJSON(service, body); errMsg.Text != "" {
+			errMsg.Code = resp.StatusCode
+			return errMsg
+		}
+	}
+
+	// the chain and hostname are already verified by the client
+	if service.TLS.ExpiryDays > 0 && resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		if errMsg := certificateExpiry(service, resp.TLS.PeerCertificates[0]); errMsg.Text != "" {
+			errMsg.Code = resp.StatusCode
+			return errMsg
+		}
 	}
 
 	if service.Response.Body != "" {
@@ -110,7 +248,32 @@ func (h Handler) CheckService(service config.Service) error {
 					Code: resp.StatusCode,
 					Err:  nil,
 				}
-				return sendAlerts(service, errMsg)
+				return errMsg
+			}
+		case service.Response.Compare == "regex" || service.Response.Compare == "not-regex":
+			pattern := service.Response.Regexp
+			if pattern == nil {
+				pattern, err = regexp.Compile(service.Response.Body)
+				if err != nil {
+					errMsg := sender.Response{
+						Text: "Invalid response body pattern '" + service.Response.Body + "'",
+						Code: resp.StatusCode,
+						Err:  err,
+					}
+					return errMsg
+				}
+			}
+			if matched := pattern.Match(body); matched != (service.Response.Compare == "regex") {
+				text := "Body does not match pattern '" + service.Response.Body + "'"
+				if matched {
+					text = "Body matches pattern '" + service.Response.Body + "'"
+				}
+				errMsg := sender.Response{
+					Text: text,
+					Code: resp.StatusCode,
+					Err:  nil,
+				}
+				return errMsg
 			}
 		default:
 			if string(body) != service.Response.Body {
@@ -119,18 +282,35 @@ func (h Handler) CheckService(service config.Service) error {
 					Code: resp.StatusCode,
 					Err:  nil,
 				}
-				return sendAlerts(service, errMsg)
+				return errMsg
 			}
 		}
 	}
 
-	return sendAlerts(service, sender.Response{Code: 200})
+	return sender.Response{Code: resp.StatusCode}
+}
+
+func timeout(service config.Service) time.Duration {
+	if service.Timeout == "" {
+		return DEFAULT_TIMEOUT
+	}
+	// validated when the config is loaded
+	t, err := time.ParseDuration(service.Timeout)
+	if err != nil || t <= 0 {
+		return DEFAULT_TIMEOUT
+	}
+	return t
 }
 
-func sendAlerts(service config.Service, response sender.Response) error {
+// sendAlerts updates the thresholds of every alert and returns the alerts to deliver
+// together with the alert states changed by the check
+func (h Handler) sendAlerts(service config.Service, response sender.Response) ([]alertDelivery, map[string]store.State, error) {
 	thresholdMutex.Lock()
 	defer thresholdMutex.Unlock()
 	errs := errors.New("")
+	var deliveries []alertDelivery
+	states := make(map[string]store.State)
+	now := time.Now()
 	for _, alert := range service.Alerts {
 		msg := sender.Message{
 			Status:      "",
@@ -139,27 +319,73 @@ func sendAlerts(service config.Service, response sender.Response) error {
 			Url:         service.URL,
 			ServiceName: service.Name,
 			Response:    response,
+			Alert:       alert,
+			Labels:      service.Labels,
 		}
 
 		alertName := service.Name + "_" + alert.Name
+		before := alertState(alertName)
+		msg.PreviousState = LastNotified[alertName]
+		if msg.PreviousState == "" {
+			msg.PreviousState = STATUS_UP
+		}
 		if len(response.Text) > 0 {
+			LastStatus[alertName] = STATUS_DOWN
 			FailureThreshold[alertName]++
-			if FailureThreshold[alertName] == alert.Failure {
-				message := fmt.Sprintf("[%s] Service unreachable", service.Name)
+			if DownSince[alertName].IsZero() {
+				DownSince[alertName] = now
+			}
+			due := FailureThreshold[alertName] == alert.Failure
+			if escalated(service, alert) {
+				due = escalationDue(service, alert, alertName, now)
+			}
+			if due {
+				status := "Service unreachable"
+				if response.Status != "" {
+					status = response.Status
+				}
+				message := fmt.Sprintf("[%s] %s", service.Name, status)
 				msg.Status = message
-				err := sendAlert(alert, msg)
+				msg.Event = sender.EVENT_DOWN
+				if response.Event != "" {
+					msg.Event = response.Event
+				}
+				msg.CurrentState = STATUS_DOWN
+				msg.Failures = FailureThreshold[alertName]
+				msg.Duration = time.Since(DownSince[alertName])
+				delivery, err := h.newAlertDelivery(alertName, alert, msg)
+				if err == nil {
+					deliveries = append(deliveries, delivery)
+				}
 				errs = errors.Join(errs, err)
+				LastAlert[alertName] = time.Now()
+				LastNotified[alertName] = STATUS_DOWN
 			}
 		} else {
+			LastStatus[alertName] = STATUS_UP
 			if SuccessThreshold[alertName]+1 >= alert.Success && FailureThreshold[alertName] != 0 {
-				if alert.SendOnResolve {
+				// an escalated alert hears about the recovery only if it was told about the outage
+				if alert.SendOnResolve && (!escalated(service, alert) || notified(alertName)) {
 					resolveMessage := fmt.Sprintf("[%s] Service has recovered", service.Name)
 					msg.Status = resolveMessage
-					err := sendAlert(alert, msg)
+					msg.Event = sender.EVENT_UP
+					msg.CurrentState = STATUS_UP
+					msg.Failures = FailureThreshold[alertName]
+					if !DownSince[alertName].IsZero() {
+						msg.Duration = time.Since(DownSince[alertName])
+					}
+					delivery, err := h.newAlertDelivery(alertName, alert, msg)
+					if err == nil {
+						deliveries = append(deliveries, delivery)
+					}
 					errs = errors.Join(errs, err)
+					LastAlert[alertName] = time.Now()
+					LastNotified[alertName] = STATUS_UP
 				}
 				FailureThreshold[alertName] = 0
 				SuccessThreshold[alertName] = 0
+				delete(DownSince, alertName)
+				delete(Acknowledged, alertName)
 			}
 			if FailureThreshold[alertName] > 0 {
 				SuccessThreshold[alertName]++
@@ -172,22 +398,94 @@ func sendAlerts(service config.Service, response sender.Response) error {
 		if SuccessThreshold[alertName] > LIMIT_MAX_SUCCESS {
 			SuccessThreshold[alertName] = 0
 		}
+
+		metrics.SetConsecutiveFailures(service.Name, alert.Name, FailureThreshold[alertName])
+		if state := alertState(alertName); state != before {
+			states[alertName] = stamped(state)
+		}
 	}
 
-	return errs
+	return deliveries, states, errs
 }
 
-func sendAlert(alert config.Alert, message sender.Message) error {
-	sendService, err := sender.NewSender(alert.Type, message)
-	fmt.Println("sendService", sendService)
-	if err != nil {
-		log.Printf("Error creating alert sender: %s", err)
-		return err
+// alertState takes a snapshot of the alert state, must be called with thresholdMutex held
+func alertState(alertName string) store.State {
+	return store.State{
+		Failures:     FailureThreshold[alertName],
+		Successes:    SuccessThreshold[alertName],
+		LastStatus:   LastStatus[alertName],
+		LastAlert:    LastAlert[alertName],
+		LastNotified: LastNotified[alertName],
+		SlackThread:  SlackThread[alertName],
+		DownSince:    DownSince[alertName],
+		Acknowledged: Acknowledged[alertName],
 	}
-	err = sendService.Send()
+}
+
+// stateSeq orders the state snapshots, so a snapshot saved late doesn't overwrite a newer one.
+// Guarded by thresholdMutex.
+var stateSeq uint64
+
+// stamped gives the snapshot the next sequence number, must be called with thresholdMutex held
+func stamped(state store.State) store.State {
+	stateSeq++
+	state.Seq = stateSeq
+	return state
+}
+
+// saveStates persists alert states, it is called after thresholdMutex is released
+// so other checks don't wait for the disk
+func saveStates(st store.Store, states map[string]store.State) {
+	if st == nil {
+		return
+	}
+	for alertName, state := range states {
+		if err := st.Save(alertName, state); err != nil {
+			log.Printf("[ERROR] failed to save state of %s, %v", alertName, err)
+		}
+	}
+}
+
+// RestoreState loads alert states saved by a previous run into the threshold maps
+func RestoreState(st store.Store) error {
+	states, err := st.Load()
 	if err != nil {
-		log.Printf("Error sending alert: %s", err)
 		return err
 	}
+
+	thresholdMutex.Lock()
+	defer thresholdMutex.Unlock()
+	for alertName, state := range states {
+		if state.Seq > stateSeq {
+			stateSeq = state.Seq
+		}
+		FailureThreshold[alertName] = state.Failures
+		SuccessThreshold[alertName] = state.Successes
+		LastStatus[alertName] = state.LastStatus
+		LastAlert[alertName] = state.LastAlert
+		if state.LastNotified != "" {
+			LastNotified[alertName] = state.LastNotified
+		}
+		if state.SlackThread != "" {
+			SlackThread[alertName] = state.SlackThread
+		}
+		if !state.DownSince.IsZero() {
+			DownSince[alertName] = state.DownSince
+		}
+		if !state.Acknowledged.IsZero() {
+			Acknowledged[alertName] = state.Acknowledged
+		}
+	}
+	log.Printf("[INFO] restored %d alert states", len(states))
 	return nil
 }
+
+// newAlertDelivery creates the sender of the alert, an unsupported sender type is reported at once
+func (h Handler) newAlertDelivery(alertName string, alert config.Alert, message sender.Message) (alertDelivery, error) {
+	sendService, err := sender.NewSender(alert.Type, message)
+	if err != nil {
+		log.Printf("Error creating alert sender: %s", err)
+		return alertDelivery{}, err
+	}
+	return alertDelivery{key: alertName, alert: alert, message: message, sender: sendService, store: h.Store}, nil
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"micro-pinger/v2/app/metrics"
 	config "micro-pinger/v2/app/service"
+	"micro-pinger/v2/app/store"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -282,3 +285,216 @@ func TestBadRequestMethod(t *testing.T) {
 	handler := NewHandler([]config.Service{sampleService}, client)
 	handler.CheckService(sampleService)
 }
+
+func TestCheckServiceCompareRegex(t *testing.T) {
+	testCases := []struct {
+		name     string
+		compare  string
+		pattern  string
+		body     string
+		expected string
+	}{
+		{name: "RegexMatch", compare: "regex", pattern: `build [0-9a-f]{7}`, body: "app build 1a2b3c4 at 12:00"},
+		{name: "RegexNoMatch", compare: "regex", pattern: `^v\d+`, body: "version unknown", expected: `Body does not match pattern '^v\d+'`},
+		{name: "NotRegexMatch", compare: "not-regex", pattern: `(?i)error`, body: "Internal Error", expected: "Body matches pattern '(?i)error'"},
+		{name: "NotRegexNoMatch", compare: "not-regex", pattern: `(?i)error`, body: "all good"},
+		{name: "InvalidPattern", compare: "regex", pattern: `v(1`, body: "v1", expected: "Invalid response body pattern 'v(1'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sampleService := config.Service{
+				Name: "SampleService_Regex",
+				URL:  "https://example.com",
+				Response: config.Response{
+					Status:  http.StatusOK,
+					Compare: tc.compare,
+					Body:    tc.pattern,
+				},
+			}
+			handler := NewHandler([]config.Service{sampleService}, &MockHTTPClient{StatusCode: http.StatusOK, Body: tc.body})
+			response := handler.checkHTTP(sampleService)
+			assert.Equal(t, tc.expected, response.Text)
+		})
+	}
+}
+
+func TestCheckServiceMaxLatency(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("OK"))
+	}))
+	defer mockServer.Close()
+
+	serviceName := "SampleService_Latency"
+	sampleService := config.Service{
+		Name: serviceName,
+		URL:  mockServer.URL,
+		Response: config.Response{
+			Status:     http.StatusOK,
+			Body:       "OK",
+			MaxLatency: "10ms",
+		},
+		Alerts: []config.Alert{
+			{Name: "SampleAlert", Type: "slack", Failure: 3, Success: 2},
+		},
+	}
+	handler := NewHandler([]config.Service{sampleService}, &http.Client{})
+
+	handler.CheckService(sampleService)
+	assert.Equal(t, 1, FailureThreshold[serviceName+"_SampleAlert"])
+
+	sampleService.Response.MaxLatency = "5s"
+	handler.CheckService(sampleService)
+	assert.Equal(t, 1, SuccessThreshold[serviceName+"_SampleAlert"])
+}
+
+func TestCheckServiceStateStore(t *testing.T) {
+	serviceName := "SampleService_State"
+	sampleService := config.Service{
+		Name:     serviceName,
+		URL:      "https://example.com",
+		Response: config.Response{Status: http.StatusOK},
+		Alerts: []config.Alert{
+			{Name: "SampleAlert", Type: "not_found", Failure: 2, Success: 2},
+		},
+	}
+	st := store.NewMemory()
+	handler := NewHandler([]config.Service{sampleService}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+	handler.Store = st
+
+	handler.CheckService(sampleService)
+	handler.CheckService(sampleService)
+
+	states, err := st.Load()
+	assert.NoError(t, err)
+	state := states[serviceName+"_SampleAlert"]
+	assert.Equal(t, 2, state.Failures)
+	assert.Equal(t, STATUS_DOWN, state.LastStatus)
+	assert.False(t, state.LastAlert.IsZero())
+	assert.Equal(t, STATUS_DOWN, state.LastNotified)
+
+	// simulate a restart in the middle of an outage
+	delete(FailureThreshold, serviceName+"_SampleAlert")
+	delete(LastStatus, serviceName+"_SampleAlert")
+	delete(LastNotified, serviceName+"_SampleAlert")
+	assert.NoError(t, RestoreState(st))
+	assert.Equal(t, 2, FailureThreshold[serviceName+"_SampleAlert"])
+	assert.Equal(t, STATUS_DOWN, LastStatus[serviceName+"_SampleAlert"])
+	assert.Equal(t, STATUS_DOWN, LastNotified[serviceName+"_SampleAlert"])
+
+	// the third failure doesn't repeat the "unreachable" alert
+	lastAlert := LastAlert[serviceName+"_SampleAlert"]
+	handler.CheckService(sampleService)
+	assert.Equal(t, 3, FailureThreshold[serviceName+"_SampleAlert"])
+	assert.Equal(t, lastAlert, LastAlert[serviceName+"_SampleAlert"])
+
+	// snapshots taken after the restart are newer than the restored one
+	states, err = st.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, states[serviceName+"_SampleAlert"].Failures)
+	assert.Greater(t, states[serviceName+"_SampleAlert"].Seq, state.Seq)
+}
+
+func TestCheckServiceMetrics(t *testing.T) {
+	serviceName := "SampleService_Metrics"
+	sampleService := config.Service{
+		Name:     serviceName,
+		URL:      "https://example.com",
+		Response: config.Response{Status: http.StatusOK},
+		Alerts: []config.Alert{
+			{Name: "SampleAlert", Type: "not_found", Failure: 1, Success: 1},
+		},
+	}
+	handler := NewHandler([]config.Service{sampleService}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+	handler.CheckService(sampleService)
+	handler.CheckService(sampleService)
+
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetValue() == serviceName {
+					values[family.GetName()] = metric.GetGauge().GetValue() + metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	assert.Equal(t, 0.0, values["pinger_service_up"])
+	assert.Equal(t, 2.0, values["pinger_checks_total"])
+	assert.Equal(t, 2.0, values["pinger_consecutive_failures"])
+}
+
+func TestCheckServiceResolvedMessage(t *testing.T) {
+	var actions []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			EventAction string `json:"event_action"`
+		}
+		json.NewDecoder(r.Body).Decode(&event)
+		actions = append(actions, event.EventAction)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer mockServer.Close()
+
+	sampleService := config.Service{
+		Name:     "SampleService_Resolved",
+		URL:      "https://example.com",
+		Response: config.Response{Status: http.StatusOK},
+		Alerts: []config.Alert{
+			{Name: "SampleAlert", Type: "pagerduty", RoutingKey: "key", APIURL: mockServer.URL, Failure: 1, Success: 1, SendOnResolve: true},
+		},
+	}
+
+	handler := NewHandler([]config.Service{sampleService}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+	handler.CheckService(sampleService)
+	handler.Client = &MockHTTPClient{StatusCode: http.StatusOK}
+	handler.CheckService(sampleService)
+	handler.Delivery.Wait()
+
+	assert.Equal(t, []string{"trigger", "resolve"}, actions)
+}
+
+func TestCheckServiceAlertEvents(t *testing.T) {
+	var events []map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&event)
+		events = append(events, event)
+	}))
+	defer mockServer.Close()
+
+	sampleService := config.Service{
+		Name:     "SampleService_Events",
+		URL:      "https://example.com",
+		Response: config.Response{Status: http.StatusOK},
+		Alerts: []config.Alert{
+			{
+				Name:          "SampleAlert",
+				Type:          "webhook",
+				Webhook:       mockServer.URL,
+				Payload:       `{"event": {{json .Event}}, "previous": {{json .PreviousState}}, "current": {{json .CurrentState}}, "failures": {{.Failures}}}`,
+				Failure:       2,
+				Success:       1,
+				SendOnResolve: true,
+			},
+		},
+	}
+
+	handler := NewHandler([]config.Service{sampleService}, &MockHTTPClient{StatusCode: http.StatusBadGateway})
+	handler.CheckService(sampleService)
+	handler.CheckService(sampleService)
+	assert.False(t, DownSince[sampleService.Name+"_SampleAlert"].IsZero())
+
+	handler.Client = &MockHTTPClient{StatusCode: http.StatusOK}
+	handler.CheckService(sampleService)
+	assert.True(t, DownSince[sampleService.Name+"_SampleAlert"].IsZero())
+	handler.Delivery.Wait()
+
+	assert.Equal(t, []map[string]interface{}{
+		{"event": "down", "previous": "up", "current": "down", "failures": 2.0},
+		{"event": "up", "previous": "down", "current": "up", "failures": 2.0},
+	}, events)
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"micro-pinger/v2/app/sender"
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// checkHeaders evaluates service.Response.Headers expectations and reports the first offending header
+func checkHeaders(service config.Service, headers http.Header) sender.Response {
+	for _, expected := range service.Response.Headers {
+		values, present := headers[http.CanonicalHeaderKey(expected.Name)]
+		value := strings.Join(values, ", ")
+
+		if expected.Compare == "absent" {
+			if present {
+				return sender.Response{Text: "Header " + expected.Name + " is present"}
+			}
+			continue
+		}
+		if !present {
+			return sender.Response{Text: "Header " + expected.Name + " is missing"}
+		}
+
+		switch expected.Compare {
+		case "present":
+		case "contains":
+			if !strings.Contains(value, expected.Value) {
+				return sender.Response{Text: "Header " + expected.Name + " '" + value + "' does not contain '" + expected.Value + "'"}
+			}
+		case "regex":
+			pattern := expected.Regexp
+			if pattern == nil {
+				var err error
+				pattern, err = regexp.Compile(expected.Value)
+				if err != nil {
+					return sender.Response{Text: "Invalid pattern for header " + expected.Name, Err: err}
+				}
+			}
+			if !pattern.MatchString(value) {
+				return sender.Response{Text: "Header " + expected.Name + " '" + value + "' does not match pattern '" + expected.Value + "'"}
+			}
+		default:
+			if value != expected.Value {
+				return sender.Response{Text: "Header " + expected.Name + " is '" + value + "', expected '" + expected.Value + "'"}
+			}
+		}
+	}
+
+	return sender.Response{}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHeaders(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("Content-Type", "text/html; charset=utf-8")
+	headers.Set("Cache-Control", "public, max-age=600")
+	headers.Set("Server", "nginx")
+
+	testCases := []struct {
+		name     string
+		header   config.ResponseHeader
+		expected string
+	}{
+		{name: "Equals", header: config.ResponseHeader{Name: "server", Value: "nginx"}},
+		{
+			name:     "NotEquals",
+			header:   config.ResponseHeader{Name: "Content-Type", Value: "application/json", Compare: "equals"},
+			expected: "Header Content-Type is 'text/html; charset=utf-8', expected 'application/json'",
+		},
+		{name: "Contains", header: config.ResponseHeader{Name: "Cache-Control", Value: "max-age", Compare: "contains"}},
+		{
+			name:     "NotContains",
+			header:   config.ResponseHeader{Name: "Cache-Control", Value: "no-store", Compare: "contains"},
+			expected: "Header Cache-Control 'public, max-age=600' does not contain 'no-store'",
+		},
+		{name: "Regex", header: config.ResponseHeader{Name: "Cache-Control", Value: `max-age=\d+`, Compare: "regex"}},
+		{
+			name:     "NotRegex",
+			header:   config.ResponseHeader{Name: "Content-Type", Value: `^application/`, Compare: "regex"},
+			expected: "Header Content-Type 'text/html; charset=utf-8' does not match pattern '^application/'",
+		},
+		{name: "Present", header: config.ResponseHeader{Name: "Server", Compare: "present"}},
+		{
+			name:     "Missing",
+			header:   config.ResponseHeader{Name: "Strict-Transport-Security", Compare: "present"},
+			expected: "Header Strict-Transport-Security is missing",
+		},
+		{name: "Absent", header: config.ResponseHeader{Name: "X-Powered-By", Compare: "absent"}},
+		{name: "NotAbsent", header: config.ResponseHeader{Name: "Server", Compare: "absent"}, expected: "Header Server is present"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := config.Service{Response: config.Response{Headers: []config.ResponseHeader{tc.header}}}
+			response := checkHeaders(service, headers)
+			assert.Equal(t, tc.expected, response.Text)
+		})
+	}
+}
+
+func TestCheckServiceHeaders(t *testing.T) {
+	sampleService := config.Service{
+		Name: "SampleService_Headers",
+		URL:  "https://example.com",
+		Response: config.Response{
+			Status: http.StatusOK,
+			Headers: []config.ResponseHeader{
+				{Name: "Cache-Control", Compare: "present"},
+			},
+		},
+	}
+	handler := NewHandler([]config.Service{sampleService}, &MockHTTPClient{StatusCode: http.StatusOK, Body: "OK"})
+
+	response := handler.checkHTTP(sampleService)
+	assert.Equal(t, "Header Cache-Control is missing", response.Text)
+}
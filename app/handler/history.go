@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"micro-pinger/v2/app/sender"
+	config "micro-pinger/v2/app/service"
+	"micro-pinger/v2/app/store"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+const (
+	DEFAULT_HISTORY_LIMIT = 100
+	DEFAULT_UPTIME_WINDOW = "30d"
+)
+
+func (h Handler) recordResult(service config.Service, response sender.Response) {
+	if h.Store == nil {
+		return
+	}
+	checked := newResult(service, response, false)
+	result := store.Result{
+		Time:    time.Now(),
+		Success: checked.Success,
+		Latency: checked.Latency,
+		Code:    checked.Code,
+		Error:   checked.Error,
+	}
+	if err := h.Store.AddResult(service.Name, result); err != nil {
+		log.Printf("[ERROR] failed to save result of %s, %v", service.Name, err)
+	}
+}
+
+// History returns the latest results of a service, newest first.
+// GET /services/{name}/history?limit=100&window=24h
+func (h Handler) History(w http.ResponseWriter, r *http.Request) {
+	name, ok := h.historyService(w, r)
+	if !ok {
+		return
+	}
+
+	limit := DEFAULT_HISTORY_LIMIT
+	if value := r.URL.Query().Get("limit"); value != "" {
+		l, err := strconv.Atoi(value)
+		if err != nil || l <= 0 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, JSON{"error": "invalid limit " + value})
+			return
+		}
+		limit = l
+	}
+
+	since := time.Time{}
+	if value := r.URL.Query().Get("window"); value != "" {
+		window, err := parseWindow(value)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, JSON{"error": err.Error()})
+			return
+		}
+		since = time.Now().Add(-window)
+	}
+
+	results, err := h.Store.Results(name, since)
+	if err != nil {
+		log.Printf("[ERROR] failed to load history of %s, %v", name, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, JSON{"error": "failed to load history"})
+		return
+	}
+
+	history := make([]store.Result, 0, limit)
+	for i := len(results) - 1; i >= 0 && len(history) < limit; i-- {
+		history = append(history, results[i])
+	}
+	render.JSON(w, r, JSON{"service": name, "results": history})
+}
+
+// Uptime returns the share of successful checks of a service in percent. The history may be shorter
+// than the window, from is the time of the oldest check the uptime is computed over.
+// GET /services/{name}/uptime?window=30d
+func (h Handler) Uptime(w http.ResponseWriter, r *http.Request) {
+	name, ok := h.historyService(w, r)
+	if !ok {
+		return
+	}
+
+	value := r.URL.Query().Get("window")
+	if value == "" {
+		value = DEFAULT_UPTIME_WINDOW
+	}
+	window, err := parseWindow(value)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, JSON{"error": err.Error()})
+		return
+	}
+
+	results, err := h.Store.Results(name, time.Now().Add(-window))
+	if err != nil {
+		log.Printf("[ERROR] failed to load history of %s, %v", name, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, JSON{"error": "failed to load history"})
+		return
+	}
+
+	failures := 0
+	for _, result := range results {
+		if !result.Success {
+			failures++
+		}
+	}
+	var uptime *float64
+	var from *time.Time
+	if len(results) > 0 {
+		percent := float64(len(results)-failures) * 100 / float64(len(results))
+		uptime = &percent
+		from = &results[0].Time
+	}
+
+	render.JSON(w, r, JSON{
+		"service":  name,
+		"window":   value,
+		"from":     from,
+		"checks":   len(results),
+		"failures": failures,
+		"uptime":   uptime,
+	})
+}
+
+// historyService resolves {name} to a configured service and writes an error response if it can't
+func (h Handler) historyService(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if h.Store == nil {
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, JSON{"error": "history is not enabled"})
+		return "", false
+	}
+
+	name := chi.URLParam(r, "name")
+	for _, service := range h.Services {
+		if service.Name == name {
+			return name, true
+		}
+	}
+
+	render.Status(r, http.StatusNotFound)
+	render.JSON(w, r, JSON{"error": "service " + name + " not found"})
+	return "", false
+}
+
+// parseWindow accepts Go durations and a number of days like 30d
+func parseWindow(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if window, err := time.ParseDuration(value); err == nil && window > 0 {
+		return window, nil
+	}
+	return 0, fmt.Errorf("invalid window %s", value)
+}
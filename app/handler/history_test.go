@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	config "micro-pinger/v2/app/service"
+	"micro-pinger/v2/app/store"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func historyRouter(h Handler) chi.Router {
+	router := chi.NewRouter()
+	router.Get("/services/{name}/history", h.History)
+	router.Get("/services/{name}/uptime", h.Uptime)
+	return router
+}
+
+func TestHistory(t *testing.T) {
+	sampleService := config.Service{Name: "SampleService_History", URL: "https://example.com", Response: config.Response{Status: http.StatusOK}}
+	st := store.NewMemory()
+
+	handler := NewHandler([]config.Service{sampleService}, &MockHTTPClient{StatusCode: http.StatusOK})
+	handler.Store = st
+	handler.CheckService(sampleService)
+	handler.CheckService(sampleService)
+	handler.Client = &MockHTTPClient{StatusCode: http.StatusBadGateway}
+	handler.CheckService(sampleService)
+
+	router := historyRouter(handler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/services/SampleService_History/history?limit=2", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var history struct {
+		Service string         `json:"service"`
+		Results []store.Result `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	assert.Equal(t, "SampleService_History", history.Service)
+	require.Len(t, history.Results, 2)
+	assert.False(t, history.Results[0].Success)
+	assert.Equal(t, http.StatusBadGateway, history.Results[0].Code)
+	assert.Equal(t, "Unexpected response status", history.Results[0].Error)
+	assert.True(t, history.Results[1].Success)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/services/SampleService_History/uptime?window=30d", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var uptime struct {
+		Window   string     `json:"window"`
+		From     *time.Time `json:"from"`
+		Checks   int        `json:"checks"`
+		Failures int        `json:"failures"`
+		Uptime   *float64   `json:"uptime"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &uptime))
+	assert.Equal(t, "30d", uptime.Window)
+	require.NotNil(t, uptime.From)
+	assert.WithinDuration(t, time.Now(), *uptime.From, time.Minute, "history covers only the last checks")
+	assert.Equal(t, 3, uptime.Checks)
+	assert.Equal(t, 1, uptime.Failures)
+	require.NotNil(t, uptime.Uptime)
+	assert.InDelta(t, 66.67, *uptime.Uptime, 0.01)
+}
+
+func TestHistoryErrors(t *testing.T) {
+	sampleService := config.Service{Name: "SampleService_History"}
+	handler := NewHandler([]config.Service{sampleService}, &MockHTTPClient{})
+
+	w := httptest.NewRecorder()
+	historyRouter(handler).ServeHTTP(w, httptest.NewRequest("GET", "/services/SampleService_History/history", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	handler.Store = store.NewMemory()
+	router := historyRouter(handler)
+
+	testCases := []struct {
+		url  string
+		code int
+	}{
+		{url: "/services/unknown/history", code: http.StatusNotFound},
+		{url: "/services/unknown/uptime", code: http.StatusNotFound},
+		{url: "/services/SampleService_History/history?limit=-1", code: http.StatusBadRequest},
+		{url: "/services/SampleService_History/history?window=month", code: http.StatusBadRequest},
+		{url: "/services/SampleService_History/uptime?window=0d", code: http.StatusBadRequest},
+		{url: "/services/SampleService_History/uptime", code: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", tc.url, nil))
+		assert.Equal(t, tc.code, w.Code, tc.url)
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	window, err := parseWindow("30d")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, window)
+
+	window, err = parseWindow("90m")
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, window)
+
+	for _, value := range []string{"", "d", "-1d", "1w", "-5m"} {
+		_, err = parseWindow(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"micro-pinger/v2/app/jsonpath"
+	"micro-pinger/v2/app/sender"
+	config "micro-pinger/v2/app/service"
+)
+
+// checkJSON evaluates service.Response.JSON assertions against the body and reports the first failed one
+func checkJSON(service config.Service, body []byte) sender.Response {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return sender.Response{
+			Text: "Response body is not valid JSON",
+			Err:  err,
+		}
+	}
+
+	for _, expr := range service.Response.JSON {
+		assertion, err := jsonpath.Parse(expr)
+		if err != nil {
+			return sender.Response{
+				Text: "Invalid JSON assertion " + expr,
+				Err:  err,
+			}
+		}
+		actual, passed, err := assertion.Evaluate(data)
+		if err != nil {
+			return sender.Response{
+				Text: fmt.Sprintf("JSON assertion failed: %s (%s)", expr, err),
+			}
+		}
+		if !passed {
+			value, _ := json.Marshal(actual)
+			return sender.Response{
+				Text: fmt.Sprintf("JSON assertion failed: %s (actual: %s)", expr, value),
+			}
+		}
+	}
+
+	return sender.Response{}
+}
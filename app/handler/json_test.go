@@ -0,0 +1,59 @@
+package handler
+
+import (
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckJSON(t *testing.T) {
+	body := []byte(`{"status": "DOWN", "db": {"latency_ms": 350}, "items": [1, 2]}`)
+
+	testCases := []struct {
+		name     string
+		json     []string
+		expected string
+	}{
+		{name: "Passed", json: []string{`$.items.length > 0`, `$.db.latency_ms > 100`}},
+		{name: "FailedString", json: []string{`$.items.length > 0`, `$.status == "UP"`}, expected: `JSON assertion failed: $.status == "UP" (actual: "DOWN")`},
+		{name: "FailedNumber", json: []string{`$.db.latency_ms < 200`}, expected: `JSON assertion failed: $.db.latency_ms < 200 (actual: 350)`},
+		{name: "MissingPath", json: []string{`$.db.size < 200`}, expected: `JSON assertion failed: $.db.size < 200 (key "size" not found)`},
+		{name: "Invalid", json: []string{`status == "UP"`}, expected: `Invalid JSON assertion status == "UP"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := checkJSON(config.Service{Response: config.Response{JSON: tc.json}}, body)
+			assert.Equal(t, tc.expected, response.Text)
+		})
+	}
+
+	response := checkJSON(config.Service{Response: config.Response{JSON: []string{`$.status == "UP"`}}}, []byte("<html>"))
+	assert.Equal(t, "Response body is not valid JSON", response.Text)
+}
+
+func TestCheckServiceJSON(t *testing.T) {
+	serviceName := "SampleService_JSON"
+	sampleService := config.Service{
+		Name: serviceName,
+		URL:  "https://example.com",
+		Response: config.Response{
+			Status: http.StatusOK,
+			JSON:   []string{`$.status == "UP"`},
+		},
+		Alerts: []config.Alert{
+			{Name: "SampleAlert", Type: "slack", Failure: 3, Success: 2},
+		},
+	}
+
+	handler := NewHandler([]config.Service{sampleService}, &MockHTTPClient{StatusCode: http.StatusOK, Body: `{"status": "UP", "time": "2024-02-28T12:34:56"}`})
+	response := handler.checkHTTP(sampleService)
+	assert.Empty(t, response.Text)
+
+	handler = NewHandler([]config.Service{sampleService}, &MockHTTPClient{StatusCode: http.StatusOK, Body: `{"status": "DOWN"}`})
+	response = handler.checkHTTP(sampleService)
+	assert.Equal(t, `JSON assertion failed: $.status == "UP" (actual: "DOWN")`, response.Text)
+	assert.Equal(t, http.StatusOK, response.Code)
+}
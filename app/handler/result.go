@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"micro-pinger/v2/app/sender"
+	config "micro-pinger/v2/app/service"
+	"micro-pinger/v2/app/store"
+	"net/http"
+	"time"
+)
+
+// Result is the outcome of a single service check returned by the check endpoints
+type Result struct {
+	Service     string        `json:"service"`
+	Success     bool          `json:"success"`
+	Latency     time.Duration `json:"-"` // written as latency_ms
+	Code        int           `json:"code"`
+	Error       string        `json:"error,omitempty"` // failed assertion or transport error
+	AlertQueued bool          `json:"alert_queued"`    // an alert was queued for delivery, it may still fail
+}
+
+// MarshalJSON writes Latency in milliseconds
+func (r Result) MarshalJSON() ([]byte, error) {
+	type result Result
+	return json.Marshal(struct {
+		result
+		LatencyMs float64 `json:"latency_ms"`
+	}{result(r), store.Milliseconds(r.Latency)})
+}
+
+func (r *Result) UnmarshalJSON(data []byte) error {
+	type result Result
+	aux := struct {
+		*result
+		LatencyMs float64 `json:"latency_ms"`
+	}{result: (*result)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.Latency = time.Duration(aux.LatencyMs * float64(time.Millisecond))
+	return nil
+}
+
+func newResult(service config.Service, response sender.Response, alertQueued bool) Result {
+	result := Result{
+		Service:     service.Name,
+		Success:     response.Text == "",
+		Latency:     response.Latency,
+		Code:        response.Code,
+		Error:       response.Text,
+		AlertQueued: alertQueued,
+	}
+	if response.Err != nil {
+		result.Error = fmt.Sprintf("%s: %s", response.Text, response.Err)
+	}
+	return result
+}
+
+// writeResults answers 200 when every check passed and 503 otherwise, so callers can gate on the status code
+func writeResults(w http.ResponseWriter, results []Result) {
+	status := "ok"
+	for _, result := range results {
+		if !result.Success {
+			status = "fail"
+		}
+	}
+	if status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(JSON{"status": status, "results": results})
+}
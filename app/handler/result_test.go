@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type checkResponse struct {
+	Status  string   `json:"status"`
+	Error   string   `json:"error"`
+	Results []Result `json:"results"`
+}
+
+func TestCheckWait(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer mockServer.Close()
+
+	services := []config.Service{
+		{Name: "SampleService_WaitUp", URL: mockServer.URL + "/up", Response: config.Response{Status: http.StatusOK, Body: "OK"}},
+		{
+			Name:     "SampleService_WaitDown",
+			URL:      mockServer.URL + "/down",
+			Response: config.Response{Status: http.StatusOK},
+			Alerts:   []config.Alert{{Name: "SampleAlert", Type: "slack", Webhook: mockServer.URL + "/hook", Failure: 1}},
+		},
+	}
+	handler := NewHandler(services, &http.Client{})
+
+	w := httptest.NewRecorder()
+	handler.Check(w, httptest.NewRequest("GET", "/check?wait=true", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	assert.Contains(t, w.Body.String(), `"latency_ms":`)
+	var response checkResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "fail", response.Status)
+	require.Len(t, response.Results, 2)
+
+	assert.Equal(t, "SampleService_WaitUp", response.Results[0].Service)
+	assert.True(t, response.Results[0].Success)
+	assert.Equal(t, http.StatusOK, response.Results[0].Code)
+	assert.Greater(t, int64(response.Results[0].Latency), int64(0))
+
+	assert.Equal(t, "SampleService_WaitDown", response.Results[1].Service)
+	assert.False(t, response.Results[1].Success)
+	assert.Equal(t, http.StatusBadGateway, response.Results[1].Code)
+	assert.Equal(t, "Unexpected response status", response.Results[1].Error)
+	assert.True(t, response.Results[1].AlertQueued)
+}
+
+func TestCheckOne(t *testing.T) {
+	services := []config.Service{
+		{Name: "SampleService_One", URL: "https://example.com", Response: config.Response{Status: http.StatusOK}},
+	}
+	handler := NewHandler(services, &MockHTTPClient{StatusCode: http.StatusOK})
+	router := chi.NewRouter()
+	router.Get("/check/{name}", handler.CheckOne)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/check/SampleService_One", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response checkResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "ok", response.Status)
+	require.Len(t, response.Results, 1)
+	assert.True(t, response.Results[0].Success)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/check/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "service unknown not found", response.Error)
+}
+
+func TestCheckOneReportsStatusCode(t *testing.T) {
+	services := []config.Service{
+		{Name: "SampleService_NoContent", URL: "https://example.com", Response: config.Response{Status: http.StatusNoContent}},
+	}
+	handler := NewHandler(services, &MockHTTPClient{StatusCode: http.StatusNoContent})
+	router := chi.NewRouter()
+	router.Get("/check/{name}", handler.CheckOne)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/check/SampleService_NoContent", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response checkResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Results, 1)
+	assert.True(t, response.Results[0].Success)
+	assert.Equal(t, http.StatusNoContent, response.Results[0].Code)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"bytes"
+	"micro-pinger/v2/app/sender"
+	config "micro-pinger/v2/app/service"
+	"net"
+	"strings"
+	"time"
+)
+
+const MAX_BANNER_SIZE = 4096
+
+// checkTCP dials service.URL (host:port), optionally writes service.Body
+// and expects service.Response.Body to be a substring of the reply
+func checkTCP(service config.Service) sender.Response {
+	address := strings.TrimPrefix(service.URL, "tcp://")
+	deadline := time.Now().Add(timeout(service))
+
+	conn, err := net.DialTimeout("tcp", address, timeout(service))
+	if err != nil {
+		return sender.Response{
+			Text: "Error connecting to " + address,
+			Err:  err,
+		}
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return sender.Response{
+			Text: "Error setting connection deadline",
+			Err:  err,
+		}
+	}
+
+	if service.Body != "" {
+		if _, err := conn.Write([]byte(service.Body)); err != nil {
+			return sender.Response{
+				Text: "Error sending payload",
+				Err:  err,
+			}
+		}
+	}
+
+	if service.Response.Body == "" {
+		return sender.Response{}
+	}
+
+	banner, err := readBanner(conn, []byte(service.Response.Body))
+	if !bytes.Contains(banner, []byte(service.Response.Body)) {
+		return sender.Response{
+			Text: "Banner does not contain expected string '" + service.Response.Body + "'",
+			Err:  err,
+		}
+	}
+
+	return sender.Response{}
+}
+
+func readBanner(conn net.Conn, expected []byte) ([]byte, error) {
+	banner := make([]byte, 0, 512)
+	buf := make([]byte, 512)
+	for len(banner) < MAX_BANNER_SIZE {
+		n, err := conn.Read(buf)
+		banner = append(banner, buf[:n]...)
+		if bytes.Contains(banner, expected) {
+			return banner, nil
+		}
+		if err != nil {
+			return banner, err
+		}
+	}
+	return banner, nil
+}
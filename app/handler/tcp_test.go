@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"bufio"
+	config "micro-pinger/v2/app/service"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTCPServer(t *testing.T, handle func(conn net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestCheckTCP(t *testing.T) {
+	address := startTCPServer(t, func(conn net.Conn) {
+		conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+	})
+
+	response := checkTCP(config.Service{Name: "ssh", Type: "tcp", URL: address})
+	assert.Empty(t, response.Text)
+
+	response = checkTCP(config.Service{
+		Name:     "ssh",
+		Type:     "tcp",
+		URL:      "tcp://" + address,
+		Response: config.Response{Body: "SSH-2.0"},
+	})
+	assert.Empty(t, response.Text)
+
+	response = checkTCP(config.Service{
+		Name:     "ssh",
+		Type:     "tcp",
+		URL:      address,
+		Timeout:  "200ms",
+		Response: config.Response{Body: "SMTP"},
+	})
+	assert.Equal(t, "Banner does not contain expected string 'SMTP'", response.Text)
+}
+
+func TestCheckTCPPayload(t *testing.T) {
+	address := startTCPServer(t, func(conn net.Conn) {
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err == nil && line == "PING\r\n" {
+			conn.Write([]byte("+PONG\r\n"))
+		}
+	})
+
+	response := checkTCP(config.Service{
+		Name:     "redis",
+		Type:     "tcp",
+		URL:      address,
+		Body:     "PING\r\n",
+		Response: config.Response{Body: "PONG"},
+	})
+	assert.Empty(t, response.Text)
+}
+
+func TestCheckTCPConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	response := checkTCP(config.Service{Name: "db", Type: "tcp", URL: address, Timeout: "200ms"})
+	assert.Equal(t, "Error connecting to "+address, response.Text)
+	assert.Error(t, response.Err)
+}
+
+func TestCheckServiceTCP(t *testing.T) {
+	serviceName := "SampleService_TCP"
+	sampleService := config.Service{
+		Name:    serviceName,
+		Type:    "tcp",
+		URL:     "127.0.0.1:1",
+		Timeout: "200ms",
+		Alerts: []config.Alert{
+			{
+				Name:    "SampleAlert",
+				Webhook: "https://hooks.slack.com/services/123456/7890",
+				Type:    "slack",
+				Failure: 3,
+				Success: 2,
+			},
+		},
+	}
+	handler := NewHandler([]config.Service{sampleService}, &MockHTTPClient{})
+	handler.CheckService(sampleService)
+
+	assert.Equal(t, 1, FailureThreshold[serviceName+"_SampleAlert"])
+}
+
+func TestCheckServiceUnsupportedType(t *testing.T) {
+	serviceName := "SampleService_Unsupported"
+	sampleService := config.Service{
+		Name: serviceName,
+		Type: "ftp",
+		Alerts: []config.Alert{
+			{Name: "SampleAlert", Type: "slack", Failure: 3, Success: 2},
+		},
+	}
+	handler := NewHandler([]config.Service{sampleService}, &MockHTTPClient{})
+	handler.CheckService(sampleService)
+
+	assert.Equal(t, 1, FailureThreshold[serviceName+"_SampleAlert"])
+}
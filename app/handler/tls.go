@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"micro-pinger/v2/app/sender"
+	config "micro-pinger/v2/app/service"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// checkTLS connects to service.URL (host:port or https URL, port 443 by default)
+// and validates the peer certificate chain, hostname and expiry
+func checkTLS(service config.Service) sender.Response {
+	host, address := tlsAddress(service.URL)
+
+	tlsConfig, err := newTLSConfig(service)
+	if err != nil {
+		return sender.Response{
+			Text: "Error creating TLS config",
+			Err:  err,
+		}
+	}
+	roots := tlsConfig.RootCAs
+	// verification is done by inspectCertificates to report a precise reason
+	tlsConfig.ServerName = host
+	tlsConfig.InsecureSkipVerify = true
+
+	dialer := &net.Dialer{Timeout: timeout(service)}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	if err != nil {
+		return sender.Response{
+			Text: "Error making TLS handshake with " + address,
+			Err:  err,
+		}
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if service.TLS.InsecureSkipVerify && len(certs) > 0 {
+		return certificateExpiry(service, certs[0])
+	}
+	return inspectCertificates(service, host, certs, roots)
+}
+
+// inspectCertificates verifies the chain against roots (system pool if nil), the hostname
+// and reports a leaf certificate expiring within service.TLS.ExpiryDays days
+func inspectCertificates(service config.Service, host string, certs []*x509.Certificate, roots *x509.CertPool) sender.Response {
+	if len(certs) == 0 {
+		return sender.Response{
+			Text:   "No peer certificates presented by " + host,
+			Status: "Certificate is invalid",
+			Event:  sender.EVENT_CERT_INVALID,
+		}
+	}
+	leaf := certs[0]
+
+	if err := leaf.VerifyHostname(host); err != nil {
+		return sender.Response{
+			Text:   "Certificate does not match hostname " + host,
+			Err:    err,
+			Status: "Certificate is invalid",
+			Event:  sender.EVENT_CERT_INVALID,
+		}
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	var invalid x509.CertificateInvalidError
+	if err != nil && !(errors.As(err, &invalid) && invalid.Reason == x509.Expired) {
+		return sender.Response{
+			Text:   "Certificate chain is untrusted",
+			Err:    err,
+			Status: "Certificate is invalid",
+			Event:  sender.EVENT_CERT_INVALID,
+		}
+	}
+
+	return certificateExpiry(service, leaf)
+}
+
+// certificateExpiry reports a leaf certificate that has expired or expires within service.TLS.ExpiryDays days
+func certificateExpiry(service config.Service, leaf *x509.Certificate) sender.Response {
+	left := time.Until(leaf.NotAfter)
+	if left <= 0 {
+		return sender.Response{
+			Text:   "Certificate expired on " + leaf.NotAfter.Format("2006-01-02 15:04:05"),
+			Status: "Certificate expired",
+			Event:  sender.EVENT_CERT_INVALID,
+		}
+	}
+	days := int(left.Hours() / 24)
+	if service.TLS.ExpiryDays > 0 && days < service.TLS.ExpiryDays {
+		return sender.Response{
+			Text:   "Certificate expires on " + leaf.NotAfter.Format("2006-01-02 15:04:05"),
+			Status: fmt.Sprintf("Certificate expires in %d days", days),
+			Event:  sender.EVENT_CERT_EXPIRING,
+		}
+	}
+
+	return sender.Response{}
+}
+
+func tlsAddress(rawURL string) (host string, address string) {
+	address = rawURL
+	if strings.Contains(rawURL, "://") {
+		if u, err := url.Parse(rawURL); err == nil {
+			address = u.Host
+		}
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host, port = address, "443"
+	}
+	return host, net.JoinHostPort(host, port)
+}
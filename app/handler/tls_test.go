@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTLSUntrusted(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	response := checkTLS(config.Service{Name: "tls", Type: "tls", URL: ts.URL, Timeout: "2s"})
+	assert.Equal(t, "Certificate chain is untrusted", response.Text)
+	assert.Equal(t, "Certificate is invalid", response.Status)
+	assert.Error(t, response.Err)
+}
+
+func TestCheckTLSConnectionRefused(t *testing.T) {
+	response := checkTLS(config.Service{Name: "tls", Type: "tls", URL: "127.0.0.1:1", Timeout: "200ms"})
+	assert.Equal(t, "Error making TLS handshake with 127.0.0.1:1", response.Text)
+}
+
+func TestInspectCertificates(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	cert, err := x509.ParseCertificate(ts.TLS.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	certs := []*x509.Certificate{cert}
+
+	response := inspectCertificates(config.Service{}, "example.com", certs, roots)
+	assert.Empty(t, response.Text)
+
+	response = inspectCertificates(config.Service{TLS: config.TLS{ExpiryDays: 30}}, "127.0.0.1", certs, roots)
+	assert.Empty(t, response.Text)
+
+	response = inspectCertificates(config.Service{TLS: config.TLS{ExpiryDays: 365 * 1000}}, "example.com", certs, roots)
+	assert.True(t, strings.HasPrefix(response.Status, "Certificate expires in "), response.Status)
+	assert.True(t, strings.HasPrefix(response.Text, "Certificate expires on "), response.Text)
+
+	response = inspectCertificates(config.Service{}, "example.org", certs, roots)
+	assert.Equal(t, "Certificate does not match hostname example.org", response.Text)
+	assert.Equal(t, "Certificate is invalid", response.Status)
+
+	response = inspectCertificates(config.Service{}, "example.com", nil, roots)
+	assert.Equal(t, "No peer certificates presented by example.com", response.Text)
+}
+
+func TestCheckServiceTLSExpiryStatus(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer ts.Close()
+
+	serviceName := "SampleService_TLS"
+	sampleService := config.Service{
+		Name:     serviceName,
+		URL:      ts.URL,
+		TLS:      config.TLS{ExpiryDays: 365 * 1000},
+		Response: config.Response{Status: http.StatusOK},
+		Alerts: []config.Alert{
+			{Name: "SampleAlert", Type: "not_found", Failure: 1, Success: 1},
+		},
+	}
+	handler := NewHandler([]config.Service{sampleService}, ts.Client())
+
+	response := handler.checkHTTP(sampleService)
+	assert.True(t, strings.HasPrefix(response.Status, "Certificate expires in "), response.Status)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	ts.Client().Transport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	sampleService.TLS.ExpiryDays = 0
+	response = handler.checkHTTP(sampleService)
+	assert.Empty(t, response.Text)
+}
+
+func TestTLSAddress(t *testing.T) {
+	host, address := tlsAddress("https://example.com/health")
+	assert.Equal(t, "example.com", host)
+	assert.Equal(t, "example.com:443", address)
+
+	host, address = tlsAddress("example.com:8443")
+	assert.Equal(t, "example.com", host)
+	assert.Equal(t, "example.com:8443", address)
+
+	host, address = tlsAddress("example.com")
+	assert.Equal(t, "example.com", host)
+	assert.Equal(t, "example.com:443", address)
+}
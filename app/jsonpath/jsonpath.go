@@ -0,0 +1,184 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Assertion is a parsed expression like `$.db.latency_ms < 200`.
+// Supported operators are ==, !=, <, <=, >, >=, without an operator the path must exist.
+// Paths start with $ and use .key, ["key"] and [index] segments, .length returns the size of an array, object or string.
+type Assertion struct {
+	Expr     string
+	path     []interface{} // string keys and int indexes
+	op       string
+	expected interface{}
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func Parse(expr string) (Assertion, error) {
+	assertion := Assertion{Expr: expr}
+
+	left, op, right := splitOperator(expr)
+	path, err := parsePath(strings.TrimSpace(left))
+	if err != nil {
+		return Assertion{}, fmt.Errorf("invalid assertion %q: %w", expr, err)
+	}
+	assertion.path = path
+	assertion.op = op
+
+	if op == "" {
+		return assertion, nil
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(right)), &assertion.expected); err != nil {
+		return Assertion{}, fmt.Errorf("invalid assertion %q: value must be a JSON literal: %w", expr, err)
+	}
+	if op != "==" && op != "!=" {
+		if _, ok := assertion.expected.(float64); !ok {
+			return Assertion{}, fmt.Errorf("invalid assertion %q: %s requires a number", expr, op)
+		}
+	}
+
+	return assertion, nil
+}
+
+// Evaluate applies the assertion to decoded JSON and returns the actual value found by the path
+func (a Assertion) Evaluate(data interface{}) (interface{}, bool, error) {
+	actual, err := lookup(data, a.path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch a.op {
+	case "":
+		return actual, true, nil
+	case "==":
+		return actual, reflect.DeepEqual(actual, a.expected), nil
+	case "!=":
+		return actual, !reflect.DeepEqual(actual, a.expected), nil
+	}
+
+	number, ok := actual.(float64)
+	if !ok {
+		return actual, false, nil
+	}
+	expected := a.expected.(float64)
+	switch a.op {
+	case "<":
+		return actual, number < expected, nil
+	case "<=":
+		return actual, number <= expected, nil
+	case ">":
+		return actual, number > expected, nil
+	default:
+		return actual, number >= expected, nil
+	}
+}
+
+// splitOperator finds the first operator outside of quotes and brackets
+func splitOperator(expr string) (string, string, string) {
+	quoted, depth := false, 0
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == '"' && (i == 0 || expr[i-1] != '\\'):
+			quoted = !quoted
+		case quoted:
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0:
+			for _, op := range operators {
+				if strings.HasPrefix(expr[i:], op) {
+					return expr[:i], op, expr[i+len(op):]
+				}
+			}
+		}
+	}
+	return expr, "", ""
+}
+
+func parsePath(path string) ([]interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with $")
+	}
+	var segments []interface{}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in path %s", path)
+			}
+			segments = append(segments, key)
+			rest = rest[end+1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed bracket in path %s", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			if key, err := strconv.Unquote(inner); err == nil {
+				segments = append(segments, key)
+			} else if index, err := strconv.Atoi(inner); err == nil {
+				segments = append(segments, index)
+			} else {
+				return nil, fmt.Errorf("invalid segment [%s] in path %s", inner, path)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in path %s", rest[0], path)
+		}
+	}
+	return segments, nil
+}
+
+func lookup(data interface{}, path []interface{}) (interface{}, error) {
+	current := data
+	for _, segment := range path {
+		switch segment := segment.(type) {
+		case int:
+			list, ok := current.([]interface{})
+			if !ok || segment < 0 || segment >= len(list) {
+				return nil, fmt.Errorf("index [%d] not found", segment)
+			}
+			current = list[segment]
+		case string:
+			if object, ok := current.(map[string]interface{}); ok {
+				if value, ok := object[segment]; ok {
+					current = value
+					continue
+				}
+			}
+			if segment == "length" {
+				if length, ok := size(current); ok {
+					current = float64(length)
+					continue
+				}
+			}
+			return nil, fmt.Errorf("key %q not found", segment)
+		}
+	}
+	return current, nil
+}
+
+func size(value interface{}) (int, bool) {
+	switch value := value.(type) {
+	case []interface{}:
+		return len(value), true
+	case map[string]interface{}:
+		return len(value), true
+	case string:
+		return len(value), true
+	}
+	return 0, false
+}
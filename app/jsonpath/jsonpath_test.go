@@ -0,0 +1,83 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const body = `{
+	"status": "UP",
+	"db": {"latency_ms": 120, "ok": true},
+	"items": [{"id": 1}, {"id": 2}],
+	"empty": [],
+	"nothing": null,
+	"strange key": "yes"
+}`
+
+func TestAssertion_Evaluate(t *testing.T) {
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(body), &data))
+
+	testCases := []struct {
+		expr   string
+		passed bool
+		actual interface{}
+	}{
+		{expr: `$.status == "UP"`, passed: true, actual: "UP"},
+		{expr: `$.status != "UP"`, passed: false, actual: "UP"},
+		{expr: `$.status == "DOWN"`, passed: false, actual: "UP"},
+		{expr: `$.db.latency_ms < 200`, passed: true, actual: 120.0},
+		{expr: `$.db.latency_ms <= 120`, passed: true, actual: 120.0},
+		{expr: `$.db.latency_ms > 200`, passed: false, actual: 120.0},
+		{expr: `$.db.latency_ms >= 100`, passed: true, actual: 120.0},
+		{expr: `$.db.ok == true`, passed: true, actual: true},
+		{expr: `$.items.length > 0`, passed: true, actual: 2.0},
+		{expr: `$.empty.length > 0`, passed: false, actual: 0.0},
+		{expr: `$.items[1].id == 2`, passed: true, actual: 2.0},
+		{expr: `$["strange key"] == "yes"`, passed: true, actual: "yes"},
+		{expr: `$.nothing == null`, passed: true, actual: nil},
+		{expr: `$.status > 1`, passed: false, actual: "UP"},
+		{expr: `$.db`, passed: true, actual: map[string]interface{}{"latency_ms": 120.0, "ok": true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			assertion, err := Parse(tc.expr)
+			require.NoError(t, err)
+			actual, passed, err := assertion.Evaluate(data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.passed, passed)
+			assert.Equal(t, tc.actual, actual)
+		})
+	}
+}
+
+func TestAssertion_EvaluateMissingPath(t *testing.T) {
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(body), &data))
+
+	for _, expr := range []string{`$.missing == 1`, `$.items[5].id == 1`, `$.status.length.length == 1`} {
+		assertion, err := Parse(expr)
+		require.NoError(t, err)
+		_, passed, err := assertion.Evaluate(data)
+		assert.Error(t, err, expr)
+		assert.False(t, passed, expr)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		`status == "UP"`,
+		`$.status == UP`,
+		`$.latency < "fast"`,
+		`$.items[abc] == 1`,
+		`$.items[0 == 1`,
+		`$..status == 1`,
+	} {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
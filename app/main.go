@@ -4,8 +4,10 @@ import (
 	"context"
 	"github.com/jessevdk/go-flags"
 	"log"
+	"micro-pinger/v2/app/handler"
 	server "micro-pinger/v2/app/server"
 	config "micro-pinger/v2/app/service"
+	"micro-pinger/v2/app/store"
 	"os"
 	"os/signal"
 	"syscall"
@@ -20,6 +22,9 @@ type Options struct {
 	MaxExpire      time.Duration `long:"expire" env:"MAX_EXPIRE" default:"24h" description:"max lifetime"`
 	MaxPinAttempts int           `long:"pinattempts" env:"PIN_ATTEMPTS" default:"3" description:"max attempts to enter pin"`
 	WebRoot        string        `long:"web" env:"WEB" default:"/" description:"web ui location"`
+	State          string        `long:"state" env:"STATE_FILE" description:"state file to keep alert states and history across restarts"`
+	Retention      time.Duration `long:"retention" env:"HISTORY_RETENTION" default:"744h" description:"how long check history is kept"`
+	DeadLetter     string        `long:"dead-letter" env:"DEAD_LETTER_FILE" description:"file to log alerts that could not be delivered"`
 }
 
 var revision string
@@ -39,6 +44,24 @@ func main() {
 		log.Fatal(err)
 	}
 
+	var st store.Store
+	if opts.State != "" {
+		boltStore, err := store.NewBolt(opts.State)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer boltStore.Close()
+		if err := handler.RestoreState(boltStore); err != nil {
+			log.Fatal(err)
+		}
+		boltStore.Retention = opts.Retention
+		st = boltStore
+	} else {
+		memoryStore := store.NewMemory()
+		memoryStore.Retention = opts.Retention
+		st = memoryStore
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
 		if x := recover(); x != nil {
@@ -63,6 +86,8 @@ func main() {
 		Secret:         opts.Secret,
 		Version:        revision,
 		Config:         cnf,
+		Store:          st,
+		Delivery:       handler.NewDelivery(opts.DeadLetter),
 	}
 	if err := srv.Run(ctx); err != nil {
 		log.Printf("[ERROR] failed, %+v", err)
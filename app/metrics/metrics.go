@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pinger"
+
+var (
+	Registry = prometheus.NewRegistry()
+
+	serviceUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_up",
+		Help:      "1 if the last check of the service passed, 0 otherwise.",
+	}, []string{"service"})
+	lastLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "check_last_latency_seconds",
+		Help:      "Duration of the last check of the service.",
+	}, []string{"service"})
+	latency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "check_latency_seconds",
+		Help:      "Duration of service checks.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service"})
+	checks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checks_total",
+		Help:      "Number of service checks by result.",
+	}, []string{"service", "result"})
+	consecutiveFailures = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "consecutive_failures",
+		Help:      "Failure threshold counter of the service alert.",
+	}, []string{"service", "alert"})
+	alertsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alerts_sent_total",
+		Help:      "Number of delivered alerts by sender type.",
+	}, []string{"sender"})
+	senderErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sender_errors_total",
+		Help:      "Number of failed alert deliveries by sender type.",
+	}, []string{"sender"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		serviceUp, lastLatency, latency, checks, consecutiveFailures, alertsSent, senderErrors,
+	)
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+func ObserveCheck(service string, success bool, duration time.Duration) {
+	result := "failure"
+	up := 0.0
+	if success {
+		result = "success"
+		up = 1
+	}
+	serviceUp.WithLabelValues(service).Set(up)
+	lastLatency.WithLabelValues(service).Set(duration.Seconds())
+	latency.WithLabelValues(service).Observe(duration.Seconds())
+	checks.WithLabelValues(service, result).Inc()
+}
+
+func SetConsecutiveFailures(service, alert string, failures int) {
+	consecutiveFailures.WithLabelValues(service, alert).Set(float64(failures))
+}
+
+func AlertSent(sender string) {
+	alertsSent.WithLabelValues(sender).Inc()
+}
+
+func SenderError(sender string) {
+	senderErrors.WithLabelValues(sender).Inc()
+}
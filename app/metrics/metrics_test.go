@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveCheck(t *testing.T) {
+	ObserveCheck("google", true, 250*time.Millisecond)
+	assert.Equal(t, 1.0, testutil.ToFloat64(serviceUp.WithLabelValues("google")))
+	assert.Equal(t, 0.25, testutil.ToFloat64(lastLatency.WithLabelValues("google")))
+
+	ObserveCheck("google", false, time.Second)
+	assert.Equal(t, 0.0, testutil.ToFloat64(serviceUp.WithLabelValues("google")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(checks.WithLabelValues("google", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(checks.WithLabelValues("google", "failure")))
+	assert.Equal(t, 1, testutil.CollectAndCount(latency))
+}
+
+func TestAlertCounters(t *testing.T) {
+	SetConsecutiveFailures("google", "devops", 3)
+	assert.Equal(t, 3.0, testutil.ToFloat64(consecutiveFailures.WithLabelValues("google", "devops")))
+
+	AlertSent("slack")
+	AlertSent("slack")
+	SenderError("telegram")
+	assert.Equal(t, 2.0, testutil.ToFloat64(alertsSent.WithLabelValues("slack")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(senderErrors.WithLabelValues("telegram")))
+}
+
+func TestHandler(t *testing.T) {
+	ObserveCheck("facebook", true, time.Millisecond)
+
+	ts := httptest.NewServer(Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, strings.Contains(string(body), `pinger_service_up{service="facebook"} 1`))
+	assert.True(t, strings.Contains(string(body), "go_goroutines"))
+}
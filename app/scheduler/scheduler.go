@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	config "micro-pinger/v2/app/service"
+	"sync"
+	"time"
+)
+
+type Checker interface {
+	CheckService(service config.Service) error
+}
+
+type Scheduler struct {
+	Services []config.Service
+	Checker  Checker
+}
+
+func NewScheduler(services []config.Service, checker Checker) Scheduler {
+	return Scheduler{Services: services, Checker: checker}
+}
+
+// Run starts a ticker per service with a valid interval and blocks until ctx is canceled
+func (s Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, service := range s.Services {
+		if service.Interval == "" {
+			log.Printf("[INFO] [%s] no interval, service is checked on demand only", service.Name)
+			continue
+		}
+		interval, err := time.ParseDuration(service.Interval)
+		if err != nil || interval <= 0 {
+			log.Printf("[WARN] [%s] invalid interval %q, service is not scheduled", service.Name, service.Interval)
+			continue
+		}
+		wg.Add(1)
+		go func(service config.Service, interval time.Duration) {
+			defer wg.Done()
+			s.schedule(ctx, service, interval)
+		}(service, interval)
+	}
+	wg.Wait()
+}
+
+func (s Scheduler) schedule(ctx context.Context, service config.Service, interval time.Duration) {
+	log.Printf("[INFO] [%s] scheduled every %s", service.Name, interval)
+	// spread the first checks over the whole interval so services don't fire at once
+	timer := time.NewTimer(jitter(interval))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			s.Checker.CheckService(service)
+			timer.Reset(interval + jitter(interval/10))
+		}
+	}
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"context"
+	config "micro-pinger/v2/app/service"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockChecker struct {
+	mu     sync.Mutex
+	checks map[string]int
+}
+
+func (m *MockChecker) CheckService(service config.Service) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks[service.Name]++
+	return nil
+}
+
+func (m *MockChecker) count(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checks[name]
+}
+
+func TestScheduler_Run(t *testing.T) {
+	services := []config.Service{
+		{Name: "fast", Interval: "10ms"},
+		{Name: "manual"},
+		{Name: "broken", Interval: "soon"},
+	}
+	checker := &MockChecker{checks: map[string]int{}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		NewScheduler(services, checker).Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after context cancellation")
+	}
+
+	assert.Greater(t, checker.count("fast"), 5)
+	assert.Equal(t, 0, checker.count("manual"))
+	assert.Equal(t, 0, checker.count("broken"))
+}
+
+func TestJitter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), jitter(0))
+	for i := 0; i < 100; i++ {
+		d := jitter(time.Second)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.Less(t, d, time.Second)
+	}
+}
@@ -0,0 +1,108 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DISCORD_COLOR_FAILURE = 0xE74C3C
+	DISCORD_COLOR_SUCCESS = 0x2ECC71
+	DISCORD_COLOR_WARNING = 0xF1C40F
+	// discord rejects embed field values and descriptions longer than this
+	DISCORD_MAX_FIELD_LENGTH       = 1024
+	DISCORD_MAX_DESCRIPTION_LENGTH = 4096
+)
+
+type Discord struct {
+	Message Message
+}
+
+type DiscordMessage struct {
+	Embeds []DiscordEmbed `json:"embeds"`
+}
+
+type DiscordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	URL         string         `json:"url,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []DiscordField `json:"fields"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type DiscordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func NewDiscord(message Message) Sender {
+	return &Discord{Message: message}
+}
+
+func (d *Discord) Send() error {
+	jsonMessage, err := json.Marshal(d.build())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, d.Message.Webhook, bytes.NewBuffer(jsonMessage))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doRequest(req)
+}
+
+func (d *Discord) build() DiscordMessage {
+	message := d.Message
+	embed := DiscordEmbed{
+		Title:       message.Status,
+		Description: message.Response.Text,
+		Color:       DISCORD_COLOR_SUCCESS,
+		Fields: []DiscordField{
+			{Name: "Service", Value: message.ServiceName, Inline: true},
+			{Name: "URL", Value: message.Url, Inline: true},
+		},
+		Timestamp: messageTime(message).Format(time.RFC3339),
+	}
+	if event := messageEvent(message); event.Warning() {
+		embed.Color = DISCORD_COLOR_WARNING
+	} else if event.Failure() {
+		embed.Color = DISCORD_COLOR_FAILURE
+	}
+	if text := customText(message); text != "" {
+		embed.Description = truncate(text, DISCORD_MAX_DESCRIPTION_LENGTH)
+	}
+	if message.Response.Code != 0 {
+		embed.Fields = append(embed.Fields, DiscordField{Name: "Code", Value: strconv.Itoa(message.Response.Code), Inline: true})
+	}
+	if message.Response.Latency > 0 {
+		embed.Fields = append(embed.Fields, DiscordField{Name: "Latency", Value: message.Response.Latency.Round(time.Millisecond).String(), Inline: true})
+	}
+	if message.Response.Err != nil {
+		embed.Fields = append(embed.Fields, DiscordField{Name: "Error", Value: truncate(message.Response.Err.Error(), DISCORD_MAX_FIELD_LENGTH)})
+	}
+	return DiscordMessage{Embeds: []DiscordEmbed{embed}}
+}
+
+// messageTime parses Message.Datetime set by the handler in local time
+func messageTime(message Message) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", message.Datetime, time.Local)
+	if err != nil {
+		return time.Now()
+	}
+	return t
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,80 @@
+package sender
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscord_Send(t *testing.T) {
+	var received DiscordMessage
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer mockServer.Close()
+
+	message := Message{
+		Status:      "[TestService] Service unreachable",
+		Webhook:     mockServer.URL,
+		Datetime:    "2024-02-28 12:34:56",
+		Url:         "https://example.com",
+		ServiceName: "TestService",
+		Response:    Response{Text: "Error making HTTP request", Err: errors.New("connection refused"), Code: 500},
+	}
+
+	discordSender, err := NewSender("discord", message)
+	require.NoError(t, err)
+	require.NoError(t, discordSender.Send())
+
+	require.Len(t, received.Embeds, 1)
+	embed := received.Embeds[0]
+	assert.Equal(t, "[TestService] Service unreachable", embed.Title)
+	assert.Equal(t, "Error making HTTP request", embed.Description)
+	assert.Equal(t, DISCORD_COLOR_FAILURE, embed.Color)
+	assert.Equal(t, []DiscordField{
+		{Name: "Service", Value: "TestService", Inline: true},
+		{Name: "URL", Value: "https://example.com", Inline: true},
+		{Name: "Code", Value: "500", Inline: true},
+		{Name: "Error", Value: "connection refused"},
+	}, embed.Fields)
+	expected := time.Date(2024, 2, 28, 12, 34, 56, 0, time.Local).Format(time.RFC3339)
+	assert.Equal(t, expected, embed.Timestamp)
+}
+
+func TestDiscord_Recovered(t *testing.T) {
+	discord := &Discord{Message: Message{
+		Status:      "[TestService] Service has recovered",
+		ServiceName: "TestService",
+		Response:    Response{Code: 200, Latency: 150 * time.Millisecond},
+	}}
+	embed := discord.build().Embeds[0]
+	assert.Equal(t, DISCORD_COLOR_SUCCESS, embed.Color)
+	assert.Empty(t, embed.Description)
+	assert.Equal(t, DiscordField{Name: "Latency", Value: "150ms", Inline: true}, embed.Fields[3])
+}
+
+func TestDiscord_SendError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer mockServer.Close()
+
+	err := NewDiscord(Message{Webhook: mockServer.URL}).Send()
+	assert.EqualError(t, err, "unexpected status code: 400")
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	long := strings.Repeat("é", 20)
+	assert.Equal(t, strings.Repeat("é", 9)+"…", truncate(long, 10))
+}
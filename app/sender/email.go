@@ -0,0 +1,192 @@
+package sender
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const SMTP_TIMEOUT = 10 * time.Second
+
+type Email struct {
+	Message Message
+}
+
+var emailTemplate = template.Must(template.New("email").Parse(`<html><body>
+<h3 style="color: {{if .Warning}}#f57c00{{else if .Failure}}#d32f2f{{else}}#388e3c{{end}}">{{.Status}}</h3>
+{{if .Text}}<p style="white-space: pre-wrap">{{.Text}}</p>{{else}}<table cellpadding="4">{{range .Fields}}
+<tr><td><b>{{.Name}}</b></td><td>{{.Value}}</td></tr>{{end}}
+</table>{{end}}
+</body></html>`))
+
+func NewEmail(message Message) Sender {
+	return &Email{Message: message}
+}
+
+func (e *Email) Send() error {
+	settings := e.Message.Alert.SMTP
+	if settings.Host == "" {
+		return errors.New("smtp host is not set")
+	}
+	recipients := parseRecipients(e.Message.Alert.To)
+	if len(recipients) == 0 {
+		return errors.New("no email recipients")
+	}
+	from := settings.From
+	if from == "" {
+		from = settings.Username
+	}
+
+	body, err := e.build(from, recipients)
+	if err != nil {
+		return err
+	}
+
+	client, err := e.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if settings.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", settings.Username, settings.Password, settings.Host)); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// dial connects with implicit TLS (tls), upgrades with STARTTLS (starttls, default) or stays plain (none)
+func (e *Email) dial() (*smtp.Client, error) {
+	settings := e.Message.Alert.SMTP
+	port := settings.Port
+	if port == 0 {
+		switch settings.TLS {
+		case "tls":
+			port = 465
+		case "none":
+			port = 25
+		default:
+			port = 587
+		}
+	}
+	address := net.JoinHostPort(settings.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: settings.Host}
+
+	dialer := &net.Dialer{Timeout: SMTP_TIMEOUT}
+	var conn net.Conn
+	var err error
+	if settings.TLS == "tls" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(SMTP_TIMEOUT))
+
+	client, err := smtp.NewClient(conn, settings.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if settings.TLS == "" || settings.TLS == "starttls" {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("smtp server %s does not support STARTTLS", address)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// build renders a multipart/alternative email with plain text and HTML parts
+func (e *Email) build(from string, recipients []string) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	text, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}})
+	if err != nil {
+		return nil, err
+	}
+	custom := customText(e.Message)
+	if custom != "" {
+		fmt.Fprint(text, strings.ReplaceAll(custom, "\n", "\r\n"))
+	} else {
+		for _, field := range messageFields(e.Message) {
+			fmt.Fprintf(text, "%s: %s\r\n", field.Name, field.Value)
+		}
+	}
+
+	html, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=UTF-8"}})
+	if err != nil {
+		return nil, err
+	}
+	event := messageEvent(e.Message)
+	err = emailTemplate.Execute(html, struct {
+		Status  string
+		Failure bool
+		Warning bool
+		Fields  []field
+		Text    string
+	}{e.Message.Status, event.Failure(), event.Warning(), messageFields(e.Message), custom})
+	if err != nil {
+		return nil, err
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var email bytes.Buffer
+	fmt.Fprintf(&email, "From: %s\r\n", from)
+	fmt.Fprintf(&email, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&email, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", e.Message.Status))
+	fmt.Fprintf(&email, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&email, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&email, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	email.Write(body.Bytes())
+	return email.Bytes(), nil
+}
+
+func parseRecipients(to string) []string {
+	var recipients []string
+	for _, recipient := range strings.Split(to, ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	return recipients
+}
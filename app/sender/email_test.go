@@ -0,0 +1,164 @@
+package sender
+
+import (
+	"bufio"
+	"errors"
+	config "micro-pinger/v2/app/service"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpStub is a minimal SMTP server that records envelopes and message data
+type smtpStub struct {
+	mu         sync.Mutex
+	from       string
+	recipients []string
+	data       string
+	extensions []string
+}
+
+func (s *smtpStub) start(t *testing.T) (string, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return host, p
+}
+
+func (s *smtpStub) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP stub")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			for _, extension := range s.extensions {
+				reply("250-" + extension)
+			}
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = strings.Trim(strings.TrimSpace(line)[10:], "<>")
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.mu.Lock()
+			s.recipients = append(s.recipients, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil || l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestEmail_Send(t *testing.T) {
+	stub := &smtpStub{}
+	host, port := stub.start(t)
+
+	message := Message{
+		Status:      "[TestService] Service unreachable",
+		Datetime:    "2024-02-28T12:34:56",
+		Url:         "https://example.com",
+		ServiceName: "TestService",
+		Response: Response{
+			Text: "Unexpected response status",
+			Err:  errors.New("test error"),
+			Code: 500,
+		},
+		Alert: config.Alert{
+			Type: "email",
+			To:   "devops@example.com, manager@example.com",
+			SMTP: config.SMTP{Host: host, Port: port, From: "pinger@example.com", TLS: "none"},
+		},
+	}
+
+	emailSender, err := NewSender("email", message)
+	require.NoError(t, err)
+	require.NoError(t, emailSender.Send())
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	assert.Equal(t, "pinger@example.com", stub.from)
+	assert.Equal(t, []string{"devops@example.com", "manager@example.com"}, stub.recipients)
+	assert.Contains(t, stub.data, "Subject: [TestService] Service unreachable\r\n")
+	assert.Contains(t, stub.data, "To: devops@example.com, manager@example.com\r\n")
+	assert.Contains(t, stub.data, "Content-Type: multipart/alternative; boundary=")
+	assert.Contains(t, stub.data, "Content-Type: text/plain; charset=UTF-8")
+	assert.Contains(t, stub.data, "Reason: Unexpected response status\r\n")
+	assert.Contains(t, stub.data, "Content-Type: text/html; charset=UTF-8")
+	assert.Contains(t, stub.data, "<tr><td><b>Error</b></td><td>test error</td></tr>")
+}
+
+func TestEmail_SendErrors(t *testing.T) {
+	stub := &smtpStub{}
+	host, port := stub.start(t)
+
+	testCases := []struct {
+		name  string
+		alert config.Alert
+		err   string
+	}{
+		{name: "NoHost", alert: config.Alert{To: "devops@example.com"}, err: "smtp host is not set"},
+		{name: "NoRecipients", alert: config.Alert{To: " , ", SMTP: config.SMTP{Host: host}}, err: "no email recipients"},
+		{
+			name:  "NoStartTLS",
+			alert: config.Alert{To: "devops@example.com", SMTP: config.SMTP{Host: host, Port: port}},
+			err:   "does not support STARTTLS",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewEmail(Message{ServiceName: "TestService", Alert: tc.alert}).Send()
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestParseRecipients(t *testing.T) {
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, parseRecipients(" a@example.com,b@example.com ,"))
+	assert.Empty(t, parseRecipients(""))
+}
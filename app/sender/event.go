@@ -0,0 +1,37 @@
+package sender
+
+// Event is the kind of change an alert message reports
+type Event string
+
+const (
+	EVENT_DOWN          Event = "down"          // the check failed
+	EVENT_UP            Event = "up"            // the service has recovered
+	EVENT_DEGRADED      Event = "degraded"      // the service responds slower than max-latency
+	EVENT_CERT_EXPIRING Event = "cert-expiring" // the certificate expires within expiry-days
+	EVENT_CERT_INVALID  Event = "cert-invalid"  // the certificate is expired, untrusted or issued for another host
+)
+
+// Failure reports whether the event is a problem rather than a recovery
+func (e Event) Failure() bool {
+	return e != EVENT_UP
+}
+
+// Warning reports whether the service still works, but needs attention
+func (e Event) Warning() bool {
+	return e == EVENT_DEGRADED || e == EVENT_CERT_EXPIRING
+}
+
+// messageEvent returns the event of the message, for messages built without one
+// it is taken from the check response
+func messageEvent(message Message) Event {
+	if message.Event != "" {
+		return message.Event
+	}
+	if message.Response.Event != "" {
+		return message.Response.Event
+	}
+	if message.Response.Err != nil || message.Response.Text != "" {
+		return EVENT_DOWN
+	}
+	return EVENT_UP
+}
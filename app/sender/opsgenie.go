@@ -0,0 +1,125 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	OPSGENIE_US_URL = "https://api.opsgenie.com"
+	OPSGENIE_EU_URL = "https://api.eu.opsgenie.com"
+	// opsgenie rejects alert messages longer than this
+	OPSGENIE_MAX_MESSAGE_LENGTH = 130
+)
+
+// Opsgenie creates an alert on failure and closes it on recovery, both identified by the same alias
+type Opsgenie struct {
+	Message Message
+}
+
+type OpsgenieAlert struct {
+	Message     string              `json:"message"`
+	Alias       string              `json:"alias"`
+	Description string              `json:"description,omitempty"`
+	Priority    string              `json:"priority,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Responders  []OpsgenieResponder `json:"responders,omitempty"`
+	Details     map[string]string   `json:"details,omitempty"`
+	Entity      string              `json:"entity,omitempty"`
+	Source      string              `json:"source"`
+}
+
+type OpsgenieResponder struct {
+	Type     string `json:"type"`
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+type OpsgenieClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note,omitempty"`
+}
+
+func NewOpsgenie(message Message) Sender {
+	return &Opsgenie{Message: message}
+}
+
+func (o *Opsgenie) Send() error {
+	alert := o.Message.Alert
+	if alert.APIKey == "" {
+		return errors.New("opsgenie api key is not set")
+	}
+
+	baseURL := strings.TrimSuffix(alert.APIURL, "/")
+	if baseURL == "" {
+		baseURL = OPSGENIE_US_URL
+		if strings.EqualFold(alert.Region, "eu") {
+			baseURL = OPSGENIE_EU_URL
+		}
+	}
+
+	var endpoint string
+	var payload interface{}
+	if !messageEvent(o.Message).Failure() {
+		endpoint = baseURL + "/v2/alerts/" + url.PathEscape(dedupKey(o.Message)) + "/close?identifierType=alias"
+		payload = OpsgenieClose{Source: "micro-pinger", Note: o.Message.Status}
+	} else {
+		endpoint = baseURL + "/v2/alerts"
+		payload = o.build()
+	}
+
+	jsonMessage, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(jsonMessage))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+alert.APIKey)
+	return doRequest(req)
+}
+
+func (o *Opsgenie) build() OpsgenieAlert {
+	message := o.Message
+
+	lines := make([]string, 0, 7)
+	details := map[string]string{}
+	for _, field := range messageFields(message) {
+		lines = append(lines, field.Name+": "+field.Value)
+		details[strings.ToLower(field.Name)] = field.Value
+	}
+
+	responders := make([]OpsgenieResponder, 0, len(message.Alert.Responders))
+	for _, responder := range message.Alert.Responders {
+		responders = append(responders, OpsgenieResponder{
+			Type:     responder.Type,
+			ID:       responder.ID,
+			Name:     responder.Name,
+			Username: responder.Username,
+		})
+	}
+
+	description := customText(message)
+	if description == "" {
+		description = strings.Join(lines, "\n")
+	}
+
+	return OpsgenieAlert{
+		Message:     truncate(message.Status, OPSGENIE_MAX_MESSAGE_LENGTH),
+		Alias:       dedupKey(message),
+		Description: description,
+		Priority:    message.Alert.Priority,
+		Tags:        message.Alert.Tags,
+		Responders:  responders,
+		Details:     details,
+		Entity:      message.ServiceName,
+		Source:      "micro-pinger",
+	}
+}
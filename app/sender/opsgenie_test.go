@@ -0,0 +1,92 @@
+package sender
+
+import (
+	"encoding/json"
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpsgenie_SendCreateAndClose(t *testing.T) {
+	var paths []string
+	var created OpsgenieAlert
+	var closed OpsgenieClose
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "GenieKey K3Y", r.Header.Get("Authorization"))
+		paths = append(paths, r.URL.RequestURI())
+		if r.URL.Path == "/v2/alerts" {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		} else {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&closed))
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer mockServer.Close()
+
+	alert := config.Alert{
+		Name:       "oncall",
+		Type:       "opsgenie",
+		APIKey:     "K3Y",
+		APIURL:     mockServer.URL,
+		Priority:   "P2",
+		Tags:       []string{"prod"},
+		Responders: []config.Responder{{Type: "team", Name: "ops"}},
+	}
+	message := Message{
+		Status:      "[TestService] Service unreachable",
+		Datetime:    "2024-02-28 12:34:56",
+		Url:         "https://example.com",
+		ServiceName: "TestService",
+		Response:    Response{Text: "Unexpected response status", Code: 502},
+		Alert:       alert,
+	}
+
+	opsgenieSender, err := NewSender("opsgenie", message)
+	require.NoError(t, err)
+	require.NoError(t, opsgenieSender.Send())
+
+	message.Status = "[TestService] Service has recovered"
+	message.Response = Response{Code: 200}
+	message.Event = EVENT_UP
+	require.NoError(t, NewOpsgenie(message).Send())
+
+	require.Len(t, paths, 2)
+	assert.Equal(t, "/v2/alerts", paths[0])
+	assert.Equal(t, "/v2/alerts/micro-pinger%2FTestService%2Foncall/close?identifierType=alias", paths[1])
+
+	assert.Equal(t, "[TestService] Service unreachable", created.Message)
+	assert.Equal(t, "micro-pinger/TestService/oncall", created.Alias)
+	assert.Equal(t, "P2", created.Priority)
+	assert.Equal(t, []string{"prod"}, created.Tags)
+	assert.Equal(t, []OpsgenieResponder{{Type: "team", Name: "ops"}}, created.Responders)
+	assert.Equal(t, "Unexpected response status", created.Details["reason"])
+	assert.Equal(t, "TestService", created.Entity)
+	assert.Contains(t, created.Description, "URL: https://example.com")
+
+	assert.Equal(t, "micro-pinger", closed.Source)
+	assert.Equal(t, "[TestService] Service has recovered", closed.Note)
+}
+
+func TestOpsgenie_SendErrors(t *testing.T) {
+	err := NewOpsgenie(Message{}).Send()
+	assert.EqualError(t, err, "opsgenie api key is not set")
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer mockServer.Close()
+
+	err = NewOpsgenie(Message{Alert: config.Alert{APIKey: "bad", APIURL: mockServer.URL}}).Send()
+	assert.EqualError(t, err, "unexpected status code: 401")
+}
+
+func TestOpsgenie_TruncatesMessage(t *testing.T) {
+	created := (&Opsgenie{Message: Message{Status: strings.Repeat("x", 200)}}).build()
+	assert.LessOrEqual(t, len([]rune(created.Message)), OPSGENIE_MAX_MESSAGE_LENGTH)
+}
@@ -0,0 +1,113 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const PAGERDUTY_EVENTS_URL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty sends Events API v2 trigger and resolve events with a dedup key stable per service and alert,
+// so the incident opened by the failure is closed by the recovery
+type PagerDuty struct {
+	Message Message
+}
+
+type PagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *PagerDutyPayload `json:"payload,omitempty"`
+	Links       []PagerDutyLink   `json:"links,omitempty"`
+}
+
+type PagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	Component     string            `json:"component"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type PagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+func NewPagerDuty(message Message) Sender {
+	return &PagerDuty{Message: message}
+}
+
+func (p *PagerDuty) Send() error {
+	if p.Message.Alert.RoutingKey == "" {
+		return errors.New("pagerduty routing key is not set")
+	}
+
+	jsonMessage, err := json.Marshal(p.build())
+	if err != nil {
+		return err
+	}
+
+	url := p.Message.Alert.APIURL
+	if url == "" {
+		url = PAGERDUTY_EVENTS_URL
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonMessage))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doRequest(req)
+}
+
+func (p *PagerDuty) build() PagerDutyEvent {
+	message := p.Message
+	event := PagerDutyEvent{
+		RoutingKey:  message.Alert.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    dedupKey(message),
+	}
+	kind := messageEvent(message)
+	if !kind.Failure() {
+		event.EventAction = "resolve"
+		return event
+	}
+
+	severity := message.Alert.Severity
+	if severity == "" {
+		severity = "critical"
+		if kind.Warning() {
+			severity = "warning"
+		}
+	}
+	details := map[string]string{}
+	for _, field := range messageFields(message) {
+		details[strings.ToLower(field.Name)] = field.Value
+	}
+	source := message.Url
+	if source == "" {
+		source = message.ServiceName
+	}
+	event.Payload = &PagerDutyPayload{
+		Summary:       message.Status,
+		Source:        source,
+		Severity:      severity,
+		Timestamp:     messageTime(message).Format(time.RFC3339),
+		Component:     message.ServiceName,
+		CustomDetails: details,
+	}
+	if strings.HasPrefix(message.Url, "http://") || strings.HasPrefix(message.Url, "https://") {
+		event.Links = []PagerDutyLink{{Href: message.Url, Text: message.ServiceName}}
+	}
+	return event
+}
+
+// dedupKey identifies the incident of a service alert across trigger and resolve messages
+func dedupKey(message Message) string {
+	return "micro-pinger/" + message.ServiceName + "/" + message.Alert.Name
+}
@@ -0,0 +1,75 @@
+package sender
+
+import (
+	"encoding/json"
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagerDuty_SendTriggerAndResolve(t *testing.T) {
+	var events []PagerDutyEvent
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var event PagerDutyEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer mockServer.Close()
+
+	alert := config.Alert{Name: "devops", Type: "pagerduty", RoutingKey: "R0UT1NGKEY", APIURL: mockServer.URL}
+	message := Message{
+		Status:      "[TestService] Service unreachable",
+		Datetime:    "2024-02-28 12:34:56",
+		Url:         "https://example.com",
+		ServiceName: "TestService",
+		Response:    Response{Text: "Unexpected response status", Code: 502},
+		Alert:       alert,
+	}
+
+	pagerDutySender, err := NewSender("pagerduty", message)
+	require.NoError(t, err)
+	require.NoError(t, pagerDutySender.Send())
+
+	message.Status = "[TestService] Service has recovered"
+	message.Response = Response{Code: 200}
+	message.Event = EVENT_UP
+	require.NoError(t, NewPagerDuty(message).Send())
+
+	require.Len(t, events, 2)
+	trigger, resolve := events[0], events[1]
+
+	assert.Equal(t, "trigger", trigger.EventAction)
+	assert.Equal(t, "R0UT1NGKEY", trigger.RoutingKey)
+	assert.Equal(t, "micro-pinger/TestService/devops", trigger.DedupKey)
+	require.NotNil(t, trigger.Payload)
+	assert.Equal(t, "[TestService] Service unreachable", trigger.Payload.Summary)
+	assert.Equal(t, "https://example.com", trigger.Payload.Source)
+	assert.Equal(t, "critical", trigger.Payload.Severity)
+	assert.Equal(t, "TestService", trigger.Payload.Component)
+	assert.Equal(t, "Unexpected response status", trigger.Payload.CustomDetails["reason"])
+	assert.Equal(t, []PagerDutyLink{{Href: "https://example.com", Text: "TestService"}}, trigger.Links)
+
+	assert.Equal(t, "resolve", resolve.EventAction)
+	assert.Equal(t, trigger.DedupKey, resolve.DedupKey)
+	assert.Nil(t, resolve.Payload)
+}
+
+func TestPagerDuty_SendErrors(t *testing.T) {
+	err := NewPagerDuty(Message{}).Send()
+	assert.EqualError(t, err, "pagerduty routing key is not set")
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer mockServer.Close()
+
+	err = NewPagerDuty(Message{Alert: config.Alert{RoutingKey: "key", APIURL: mockServer.URL, Severity: "warning"}}).Send()
+	assert.EqualError(t, err, "unexpected status code: 400")
+}
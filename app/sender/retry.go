@@ -0,0 +1,93 @@
+package sender
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// StatusError is returned when the alert endpoint rejects a message
+type StatusError struct {
+	Code       int
+	RetryAfter time.Duration // delay requested by the endpoint, 0 if none
+	Message    string        // overrides the default error text
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("unexpected status code: %d", e.Code)
+}
+
+// Retryable reports whether a failed delivery may succeed when repeated:
+// rate limits, server errors, transient SMTP replies and network errors
+func Retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code == http.StatusTooManyRequests || statusErr.Code >= 500
+	}
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 400 && smtpErr.Code < 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RetryAfter returns the delay requested by the endpoint that failed with err, 0 if none
+func RetryAfter(err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter parses the Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(time.Now()) {
+		return time.Until(date)
+	}
+	return 0
+}
+
+// MarshalJSON writes Err as its text, so messages can be saved and sent again
+func (r Response) MarshalJSON() ([]byte, error) {
+	type response Response
+	var errText string
+	if r.Err != nil {
+		errText = r.Err.Error()
+	}
+	return json.Marshal(struct {
+		response
+		Err string `json:",omitempty"`
+	}{response(r), errText})
+}
+
+func (r *Response) UnmarshalJSON(data []byte) error {
+	type response Response
+	aux := struct {
+		*response
+		Err string `json:",omitempty"`
+	}{response: (*response)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.Err = nil
+	if aux.Err != "" {
+		r.Err = errors.New(aux.Err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package sender
+
+import (
+	"encoding/json"
+	"errors"
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, Retryable(&StatusError{Code: http.StatusTooManyRequests}))
+	assert.True(t, Retryable(&StatusError{Code: http.StatusServiceUnavailable}))
+	assert.False(t, Retryable(&StatusError{Code: http.StatusBadRequest}))
+	assert.True(t, Retryable(&textproto.Error{Code: 421, Msg: "try again later"}))
+	assert.False(t, Retryable(&textproto.Error{Code: 550, Msg: "mailbox unavailable"}))
+	assert.False(t, Retryable(errors.New("slack channel is not set")))
+
+	_, err := http.Get("http://127.0.0.1:1")
+	assert.True(t, Retryable(err))
+}
+
+func TestDoRequestRetryAfter(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer mockServer.Close()
+
+	req, err := http.NewRequest(http.MethodPost, mockServer.URL, nil)
+	require.NoError(t, err)
+	err = doRequest(req)
+	assert.EqualError(t, err, "unexpected status code: 429")
+	assert.Equal(t, 7*time.Second, RetryAfter(err))
+
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	assert.InDelta(t, float64(time.Hour), float64(parseRetryAfter(date)), float64(2*time.Second))
+}
+
+func TestTelegram_RetryAfter(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"ok": false, "error_code": 429, "description": "Too Many Requests: retry after 3", "parameters": {"retry_after": 3}}`))
+	}))
+	defer mockServer.Close()
+
+	message := Message{Alert: config.Alert{BotToken: "123:ABC", ChatID: "42", APIURL: mockServer.URL}}
+	err := NewTelegram(message).Send()
+	assert.EqualError(t, err, "telegram api error 429: Too Many Requests: retry after 3")
+	assert.True(t, Retryable(err))
+	assert.Equal(t, 3*time.Second, RetryAfter(err))
+}
+
+func TestResponseJSON(t *testing.T) {
+	message := Message{
+		ServiceName: "TestService",
+		Response:    Response{Text: "Error making HTTP request", Err: errors.New("connection refused"), Code: 500},
+		Event:       EVENT_DOWN,
+	}
+	message.Alert.BotToken = "secret"
+
+	data, err := json.Marshal(message)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+
+	var decoded Message
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "TestService", decoded.ServiceName)
+	assert.Equal(t, EVENT_DOWN, decoded.Event)
+	assert.Equal(t, 500, decoded.Response.Code)
+	assert.EqualError(t, decoded.Response.Err, "connection refused")
+}
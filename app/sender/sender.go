@@ -2,7 +2,13 @@ package sender
 
 import (
 	"fmt"
+	"io"
 	"log"
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Message struct {
@@ -12,24 +18,52 @@ type Message struct {
 	Url         string
 	ServiceName string
 	Response    Response
+	Alert       config.Alert      `json:"-"` // settings of the alert the message is sent for, not saved as it holds secrets
+	Labels      map[string]string // labels of the service
+
+	Event         Event         // what happened, senders render the message from it
+	PreviousState string        // "up" or "down" as sent with the previous alert, "up" at first
+	CurrentState  string        // "up" or "down" after the check
+	Failures      int           // consecutive failed checks
+	Duration      time.Duration // time since the first failed check of the outage
+	ThreadTS      string        // ts of the slack outage message, the recovery is posted in its thread
 }
 
 type Response struct {
-	Text string
-	Err  error
-	Code int
+	Text    string
+	Err     error
+	Code    int
+	Status  string // overrides the default "Service unreachable" alert status
+	Event   Event  // kind of the failure, EVENT_DOWN if empty
+	Latency time.Duration
 }
 
 type Sender interface {
 	Send() error
 }
 
+const HTTP_TIMEOUT = 10 * time.Second
+
+var httpClient = &http.Client{Timeout: HTTP_TIMEOUT}
+
 func NewSender(senderType string, message Message) (Sender, error) {
 	switch senderType {
 	case "telegram":
 		return NewTelegram(message), nil
 	case "slack":
 		return NewSlack(message), nil
+	case "email":
+		return NewEmail(message), nil
+	case "webhook":
+		return NewWebhook(message), nil
+	case "discord":
+		return NewDiscord(message), nil
+	case "teams":
+		return NewTeams(message), nil
+	case "pagerduty":
+		return NewPagerDuty(message), nil
+	case "opsgenie":
+		return NewOpsgenie(message), nil
 	default:
 		log.Printf("[%s] Unsupported sender type: %s", message.ServiceName, senderType)
 		return nil, fmt.Errorf("Unsupported sender type: %s", senderType)
@@ -37,11 +71,75 @@ func NewSender(senderType string, message Message) (Sender, error) {
 }
 
 func getTextMessage(message Message) string {
+	if text := customText(message); text != "" {
+		return text
+	}
+
+	lines := make([]string, 0, 7)
+	for _, field := range messageFields(message) {
+		lines = append(lines, fmt.Sprintf("*%s:* %s", field.Name, field.Value))
+	}
+
+	return messageIcon(message) + strings.Join(lines, "\n")
+}
+
+func messageIcon(message Message) string {
+	event := messageEvent(message)
+	switch {
+	case event.Warning():
+		return "⚠️ "
+	case event.Failure():
+		return "❗"
+	default:
+		return "✅ "
+	}
+}
+
+type field struct {
+	Name  string
+	Value string
+}
+
+// messageFields lists the message details in the order every sender shows them
+func messageFields(message Message) []field {
+	fields := []field{
+		{"Service", message.ServiceName},
+		{"Status", message.Status},
+		{"Datetime", message.Datetime},
+		{"URL", message.Url},
+	}
+	if message.PreviousState != "" && message.CurrentState != "" && message.PreviousState != message.CurrentState {
+		fields = append(fields, field{"State", message.PreviousState + " → " + message.CurrentState})
+	}
+	if message.Response.Latency > 0 {
+		fields = append(fields, field{"Latency", message.Response.Latency.Round(time.Millisecond).String()})
+	}
+	if message.Failures > 0 {
+		fields = append(fields, field{"Failures", strconv.Itoa(message.Failures)})
+	}
+	if message.Duration > 0 {
+		fields = append(fields, field{"Downtime", message.Duration.Round(time.Second).String()})
+	}
+	if message.Response.Text != "" {
+		fields = append(fields, field{"Reason", message.Response.Text})
+	}
 	if message.Response.Err != nil {
-		return fmt.Sprintf("❗*Service:* %s\n*Status:* %s\n*Datetime:* %s\n*URL:* %s\n*Error:* %s",
-			message.ServiceName, message.Status, message.Datetime, message.Url, message.Response.Err.Error())
+		fields = append(fields, field{"Error", message.Response.Err.Error()})
+	}
+	return fields
+}
+
+// doRequest sends the request and fails with a StatusError on a non-2xx response
+func doRequest(req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
 	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
 
-	return fmt.Sprintf("✅ *Service:* %s\n*Status:* %s\n*Datetime:* %s\n*URL:* %s",
-		message.ServiceName, message.Status, message.Datetime, message.Url)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{Code: resp.StatusCode, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+	return nil
 }
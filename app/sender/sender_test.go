@@ -2,7 +2,9 @@ package sender
 
 import (
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSender_NotSupported(t *testing.T) {
@@ -22,3 +24,64 @@ func TestSender_NotSupported(t *testing.T) {
 	_, err := NewSender("unsupported", message)
 	assert.Error(t, err)
 }
+
+func TestGetTextMessage(t *testing.T) {
+	message := Message{
+		Status:      "[TestService] Service unreachable",
+		Datetime:    "2024-02-28T12:34:56",
+		Url:         "https://example.com",
+		ServiceName: "TestService",
+		Response: Response{
+			Text:    `JSON assertion failed: $.status == "UP" (actual: "DOWN")`,
+			Code:    200,
+			Latency: 1234567 * time.Microsecond,
+		},
+	}
+
+	assert.Equal(t, "❗*Service:* TestService\n*Status:* [TestService] Service unreachable\n*Datetime:* 2024-02-28T12:34:56\n"+
+		"*URL:* https://example.com\n*Latency:* 1.235s\n*Reason:* JSON assertion failed: $.status == \"UP\" (actual: \"DOWN\")", getTextMessage(message))
+
+	message.Response = Response{Code: 200}
+	assert.Equal(t, "✅ *Service:* TestService\n*Status:* [TestService] Service unreachable\n*Datetime:* 2024-02-28T12:34:56\n"+
+		"*URL:* https://example.com", getTextMessage(message))
+
+	// a status mismatch has no error, the event still marks it as a failure
+	message.Event = EVENT_DOWN
+	assert.True(t, strings.HasPrefix(getTextMessage(message), "❗"))
+
+	message.Event = EVENT_DEGRADED
+	assert.True(t, strings.HasPrefix(getTextMessage(message), "⚠️ "))
+}
+
+func TestMessageEvent(t *testing.T) {
+	assert.Equal(t, EVENT_UP, messageEvent(Message{}))
+	assert.Equal(t, EVENT_DOWN, messageEvent(Message{Response: Response{Text: "Unexpected response status"}}))
+	assert.Equal(t, EVENT_CERT_EXPIRING, messageEvent(Message{Response: Response{Text: "expires", Event: EVENT_CERT_EXPIRING}}))
+	assert.Equal(t, EVENT_UP, messageEvent(Message{Event: EVENT_UP, Response: Response{Text: "stale"}}))
+
+	assert.False(t, EVENT_UP.Failure())
+	assert.True(t, EVENT_CERT_INVALID.Failure())
+	assert.False(t, EVENT_CERT_INVALID.Warning())
+	assert.True(t, EVENT_DEGRADED.Failure())
+	assert.True(t, EVENT_DEGRADED.Warning())
+}
+
+func TestMessageFields_Outage(t *testing.T) {
+	message := Message{
+		ServiceName:   "TestService",
+		Status:        "[TestService] Service has recovered",
+		Event:         EVENT_UP,
+		PreviousState: "down",
+		CurrentState:  "up",
+		Failures:      3,
+		Duration:      95 * time.Second,
+	}
+
+	fields := map[string]string{}
+	for _, field := range messageFields(message) {
+		fields[field.Name] = field.Value
+	}
+	assert.Equal(t, "down → up", fields["State"])
+	assert.Equal(t, "3", fields["Failures"])
+	assert.Equal(t, "1m35s", fields["Downtime"])
+}
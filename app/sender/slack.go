@@ -3,48 +3,168 @@ package sender
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
+const (
+	SLACK_API_URL = "https://slack.com/api"
+
+	SLACK_COLOR_FAILURE = "#E74C3C"
+	SLACK_COLOR_SUCCESS = "#2ECC71"
+	SLACK_COLOR_WARNING = "#F1C40F"
+
+	// slack rejects sections with more fields or longer texts
+	SLACK_MAX_FIELDS       = 10
+	SLACK_MAX_FIELD_TEXT   = 2000
+	SLACK_MAX_SECTION_TEXT = 3000
+	SLACK_MAX_HEADER_TEXT  = 150
+)
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
 type Slack struct {
 	Message Message
+	TS      string // ts of the message posted with a bot token, the thread of the recovery
 }
 
 type SlackMessage struct {
-	Text string `json:"text"`
+	Channel     string            `json:"channel,omitempty"`
+	ThreadTS    string            `json:"thread_ts,omitempty"`
+	Text        string            `json:"text"`
+	Attachments []SlackAttachment `json:"attachments,omitempty"`
+}
+
+type SlackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []SlackBlock `json:"blocks"`
+}
+
+type SlackBlock struct {
+	Type   string      `json:"type"`
+	Text   *SlackText  `json:"text,omitempty"`
+	Fields []SlackText `json:"fields,omitempty"`
+}
+
+type SlackText struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+// SlackResponse is the envelope of Web API responses
+type SlackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	TS    string `json:"ts"`
 }
 
 func NewSlack(message Message) Sender {
 	return &Slack{Message: message}
 }
 
+// Send posts to chat.postMessage when bot-token is set, otherwise to the incoming webhook URL
 func (s *Slack) Send() error {
-	msg := getTextMessage(s.Message)
-	slackMessage := SlackMessage{Text: msg}
-	jsonMessage, err := json.Marshal(slackMessage)
+	if s.Message.Alert.BotToken != "" {
+		return s.postMessage()
+	}
+
+	jsonMessage, err := json.Marshal(s.build())
 	if err != nil {
 		return err
 	}
+	req, err := http.NewRequest(http.MethodPost, s.Message.Webhook, bytes.NewBuffer(jsonMessage))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doRequest(req)
+}
+
+// postMessage sends the message with the Web API, a recovery is posted in the thread given by Message.ThreadTS
+func (s *Slack) postMessage() error {
+	alert := s.Message.Alert
+	if alert.To == "" {
+		return errors.New("slack channel is not set")
+	}
+	baseURL := strings.TrimSuffix(alert.APIURL, "/")
+	if baseURL == "" {
+		baseURL = SLACK_API_URL
+	}
+
+	slackMessage := s.build()
+	slackMessage.Channel = alert.To
+	if !messageEvent(s.Message).Failure() {
+		slackMessage.ThreadTS = s.Message.ThreadTS
+	}
 
-	_, err = s.post(s.Message.Webhook, jsonMessage)
+	jsonMessage, err := json.Marshal(slackMessage)
 	if err != nil {
 		return err
 	}
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/chat.postMessage", bytes.NewBuffer(jsonMessage))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+alert.BotToken)
 
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{Code: resp.StatusCode, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+	var result SlackResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode slack response: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack api error: %s", result.Error)
+	}
+	s.TS = result.TS
 	return nil
 }
 
-func (s *Slack) post(url string, data []byte) (string, error) {
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
-	if err != nil {
-		return "", err
+// build renders a Block Kit message with a header, the message fields and a colour of the event
+func (s *Slack) build() SlackMessage {
+	message := s.Message
+
+	color := SLACK_COLOR_SUCCESS
+	if event := messageEvent(message); event.Warning() {
+		color = SLACK_COLOR_WARNING
+	} else if event.Failure() {
+		color = SLACK_COLOR_FAILURE
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	fields := make([]SlackText, 0, SLACK_MAX_FIELDS)
+	for _, field := range messageFields(message) {
+		if field.Name == "Status" || len(fields) == SLACK_MAX_FIELDS {
+			continue
+		}
+		text := fmt.Sprintf("*%s:*\n%s", field.Name, slackEscaper.Replace(field.Value))
+		fields = append(fields, SlackText{Type: "mrkdwn", Text: truncate(text, SLACK_MAX_FIELD_TEXT)})
 	}
 
-	return "ok", nil
+	title := message.Status
+	if title == "" {
+		title = message.ServiceName
+	}
+	blocks := []SlackBlock{
+		{Type: "header", Text: &SlackText{Type: "plain_text", Text: truncate(title, SLACK_MAX_HEADER_TEXT), Emoji: true}},
+		{Type: "section", Fields: fields},
+	}
+	if text := customText(message); text != "" {
+		blocks[1] = SlackBlock{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: truncate(text, SLACK_MAX_SECTION_TEXT)}}
+	}
+
+	return SlackMessage{
+		// shown in notifications and by clients without Block Kit support
+		Text:        getTextMessage(message),
+		Attachments: []SlackAttachment{{Color: color, Blocks: blocks}},
+	}
 }
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	config "micro-pinger/v2/app/service"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -167,3 +169,76 @@ func TestSlack_Send_Error(t *testing.T) {
 		t.Errorf("Expected error, got nil")
 	}
 }
+
+func TestSlack_BuildBlocks(t *testing.T) {
+	message := Message{
+		Status:      "[TestService] Service unreachable",
+		Url:         "https://example.com",
+		ServiceName: "TestService",
+		Response:    Response{Text: "Body does not contain expected string '<ok>'", Code: 200},
+	}
+
+	slackMessage := (&Slack{Message: message}).build()
+	assert.Equal(t, getTextMessage(message), slackMessage.Text)
+	require.Len(t, slackMessage.Attachments, 1)
+	attachment := slackMessage.Attachments[0]
+	assert.Equal(t, SLACK_COLOR_FAILURE, attachment.Color)
+	require.Len(t, attachment.Blocks, 2)
+	assert.Equal(t, "header", attachment.Blocks[0].Type)
+	assert.Equal(t, "[TestService] Service unreachable", attachment.Blocks[0].Text.Text)
+	assert.Contains(t, attachment.Blocks[1].Fields, SlackText{Type: "mrkdwn", Text: "*Service:*\nTestService"})
+	assert.Contains(t, attachment.Blocks[1].Fields, SlackText{Type: "mrkdwn", Text: "*Reason:*\nBody does not contain expected string '&lt;ok&gt;'"})
+
+	message.Event = EVENT_DEGRADED
+	assert.Equal(t, SLACK_COLOR_WARNING, (&Slack{Message: message}).build().Attachments[0].Color)
+	message.Event = EVENT_UP
+	assert.Equal(t, SLACK_COLOR_SUCCESS, (&Slack{Message: message}).build().Attachments[0].Color)
+}
+
+func TestSlack_PostMessageThreadsRecovery(t *testing.T) {
+	var received []SlackMessage
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat.postMessage", r.URL.Path)
+		assert.Equal(t, "Bearer xoxb-token", r.Header.Get("Authorization"))
+		var slackMessage SlackMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&slackMessage))
+		received = append(received, slackMessage)
+		w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1700000000.000100"}`))
+	}))
+	defer mockServer.Close()
+
+	message := Message{
+		Status:      "[ThreadService] Service unreachable",
+		ServiceName: "ThreadService",
+		Response:    Response{Text: "Unexpected response status", Code: 502},
+		Alert:       config.Alert{Name: "devops", Type: "slack", BotToken: "xoxb-token", To: "#alerts", APIURL: mockServer.URL},
+	}
+	slack := &Slack{Message: message}
+	require.NoError(t, slack.Send())
+	assert.Equal(t, "1700000000.000100", slack.TS)
+
+	message.Status = "[ThreadService] Service has recovered"
+	message.Response = Response{Code: 200}
+	message.Event = EVENT_UP
+	message.ThreadTS = slack.TS
+	require.NoError(t, NewSlack(message).Send())
+
+	require.Len(t, received, 2)
+	assert.Equal(t, "#alerts", received[0].Channel)
+	assert.Empty(t, received[0].ThreadTS)
+	assert.Equal(t, "1700000000.000100", received[1].ThreadTS)
+}
+
+func TestSlack_PostMessageErrors(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+	}))
+	defer mockServer.Close()
+
+	alert := config.Alert{BotToken: "xoxb-token", To: "#missing", APIURL: mockServer.URL}
+	err := NewSlack(Message{Alert: alert}).Send()
+	assert.EqualError(t, err, "slack api error: channel_not_found")
+
+	err = NewSlack(Message{Alert: config.Alert{BotToken: "xoxb-token"}}).Send()
+	assert.EqualError(t, err, "slack channel is not set")
+}
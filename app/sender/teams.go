@@ -0,0 +1,117 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Teams struct {
+	Message Message
+}
+
+type TeamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []TeamsAttachment `json:"attachments"`
+}
+
+type TeamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     TeamsCard `json:"content"`
+}
+
+type TeamsCard struct {
+	Schema  string        `json:"$schema"`
+	Type    string        `json:"type"`
+	Version string        `json:"version"`
+	Body    []interface{} `json:"body"`
+	Actions []TeamsAction `json:"actions,omitempty"`
+}
+
+type TeamsTextBlock struct {
+	Type   string `json:"type"`
+	Text   string `json:"text"`
+	Weight string `json:"weight,omitempty"`
+	Size   string `json:"size,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Wrap   bool   `json:"wrap"`
+}
+
+type TeamsFactSet struct {
+	Type  string      `json:"type"`
+	Facts []TeamsFact `json:"facts"`
+}
+
+type TeamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type TeamsAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+func NewTeams(message Message) Sender {
+	return &Teams{Message: message}
+}
+
+func (t *Teams) Send() error {
+	jsonMessage, err := json.Marshal(t.build())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.Message.Webhook, bytes.NewBuffer(jsonMessage))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doRequest(req)
+}
+
+func (t *Teams) build() TeamsMessage {
+	message := t.Message
+
+	color := "Good"
+	if event := messageEvent(message); event.Warning() {
+		color = "Warning"
+	} else if event.Failure() {
+		color = "Attention"
+	}
+
+	facts := make([]TeamsFact, 0, 8)
+	for _, field := range messageFields(message) {
+		facts = append(facts, TeamsFact{Title: field.Name, Value: field.Value})
+		if field.Name == "URL" && message.Response.Code != 0 {
+			facts = append(facts, TeamsFact{Title: "Code", Value: strconv.Itoa(message.Response.Code)})
+		}
+	}
+
+	card := TeamsCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []interface{}{
+			TeamsTextBlock{Type: "TextBlock", Text: message.Status, Weight: "Bolder", Size: "Medium", Color: color, Wrap: true},
+			TeamsFactSet{Type: "FactSet", Facts: facts},
+		},
+	}
+	if text := customText(message); text != "" {
+		card.Body[1] = TeamsTextBlock{Type: "TextBlock", Text: text, Wrap: true}
+	}
+	// tcp and dns services have no URL to open
+	if strings.HasPrefix(message.Url, "http://") || strings.HasPrefix(message.Url, "https://") {
+		card.Actions = []TeamsAction{{Type: "Action.OpenUrl", Title: "Open URL", URL: message.Url}}
+	}
+
+	return TeamsMessage{
+		Type: "message",
+		Attachments: []TeamsAttachment{
+			{ContentType: "application/vnd.microsoft.card.adaptive", Content: card},
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package sender
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeams_Send(t *testing.T) {
+	var received map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer mockServer.Close()
+
+	message := Message{
+		Status:      "[TestService] Service unreachable",
+		Webhook:     mockServer.URL,
+		Datetime:    "2024-02-28 12:34:56",
+		Url:         "https://example.com",
+		ServiceName: "TestService",
+		Response:    Response{Text: "Error making HTTP request", Err: errors.New("connection refused"), Code: 500},
+	}
+
+	teamsSender, err := NewSender("teams", message)
+	require.NoError(t, err)
+	require.NoError(t, teamsSender.Send())
+
+	assert.Equal(t, "message", received["type"])
+	attachment := received["attachments"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", attachment["contentType"])
+	card := attachment["content"].(map[string]interface{})
+	assert.Equal(t, "AdaptiveCard", card["type"])
+
+	body := card["body"].([]interface{})
+	title := body[0].(map[string]interface{})
+	assert.Equal(t, "[TestService] Service unreachable", title["text"])
+	assert.Equal(t, "Attention", title["color"])
+
+	facts := map[string]string{}
+	for _, fact := range body[1].(map[string]interface{})["facts"].([]interface{}) {
+		fact := fact.(map[string]interface{})
+		facts[fact["title"].(string)] = fact["value"].(string)
+	}
+	assert.Equal(t, map[string]string{
+		"Service":  "TestService",
+		"Status":   "[TestService] Service unreachable",
+		"Datetime": "2024-02-28 12:34:56",
+		"URL":      "https://example.com",
+		"Code":     "500",
+		"Reason":   "Error making HTTP request",
+		"Error":    "connection refused",
+	}, facts)
+
+	action := card["actions"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Action.OpenUrl", action["type"])
+	assert.Equal(t, "Open URL", action["title"])
+	assert.Equal(t, "https://example.com", action["url"])
+}
+
+func TestTeams_Build(t *testing.T) {
+	teams := &Teams{Message: Message{
+		Status:      "[redis] Service has recovered",
+		Url:         "redis.local:6379",
+		ServiceName: "redis",
+	}}
+	card := teams.build().Attachments[0].Content
+	assert.Equal(t, "Good", card.Body[0].(TeamsTextBlock).Color)
+	assert.Empty(t, card.Actions)
+}
+
+func TestTeams_SendError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer mockServer.Close()
+
+	err := NewTeams(Message{Webhook: mockServer.URL}).Send()
+	assert.EqualError(t, err, "unexpected status code: 400")
+}
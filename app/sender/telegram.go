@@ -3,8 +3,21 @@ package sender
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const TELEGRAM_API_URL = "https://api.telegram.org"
+
+// telegramEscaper escapes the characters reserved by the MarkdownV2 parse mode
+var telegramEscaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+	">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
 )
 
 type Telegram struct {
@@ -12,42 +25,118 @@ type Telegram struct {
 }
 
 type TelegramMessage struct {
+	ChatID    string `json:"chat_id,omitempty"`
+	ThreadID  int    `json:"message_thread_id,omitempty"`
 	Text      string `json:"text"`
-	ParseMode string `json:"parse_mode"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+// TelegramResponse is the envelope of every Bot API response
+type TelegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"` // seconds to wait when rate limited
+	} `json:"parameters"`
 }
 
 func NewTelegram(message Message) Telegram {
 	return Telegram{Message: message}
 }
 
+// Send calls sendMessage of the Bot API when bot-token is set, otherwise posts to the webhook URL
 func (t Telegram) Send() error {
+	alert := t.Message.Alert
+	endpoint := t.Message.Webhook
+	chatID := alert.ChatID
+	if chatID == "" {
+		chatID = alert.To
+	}
 
-	msg := getTextMessage(t.Message)
+	if alert.BotToken != "" {
+		if chatID == "" {
+			return errors.New("telegram chat id is not set")
+		}
+		baseURL := strings.TrimSuffix(alert.APIURL, "/")
+		if baseURL == "" {
+			baseURL = TELEGRAM_API_URL
+		}
+		endpoint = baseURL + "/bot" + alert.BotToken + "/sendMessage"
+	}
+	if endpoint == "" {
+		return errors.New("telegram bot token is not set")
+	}
 
-	telegramMessage := TelegramMessage{Text: msg, ParseMode: "markdown"}
+	telegramMessage := TelegramMessage{
+		ChatID:    chatID,
+		ThreadID:  alert.ThreadID,
+		Text:      telegramText(t.Message),
+		ParseMode: "MarkdownV2",
+	}
+	// a custom template is sent as plain text, it is not escaped
+	if text := customText(t.Message); text != "" {
+		telegramMessage.Text = text
+		telegramMessage.ParseMode = ""
+	}
 	jsonMessage, err := json.Marshal(telegramMessage)
 	if err != nil {
 		return err
 	}
 
-	_, err = t.post(t.Message.Webhook, jsonMessage)
+	return t.post(endpoint, jsonMessage)
+}
+
+func (t Telegram) post(endpoint string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(data))
 	if err != nil {
-		return err
+		return redactToken(err, t.Message.Alert.BotToken)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	return nil
-}
-
-func (t Telegram) post(url string, data []byte) (string, error) {
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return redactToken(err, t.Message.Alert.BotToken)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	// webhook relays may answer with a plain body, only a Bot API envelope is inspected
+	var result TelegramResponse
+	body, _ := io.ReadAll(resp.Body)
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+	if json.Unmarshal(body, &result) == nil && !result.OK && result.Description != "" {
+		if result.Parameters.RetryAfter > 0 {
+			retryAfter = time.Duration(result.Parameters.RetryAfter) * time.Second
+		}
+		return &StatusError{
+			Code:       result.ErrorCode,
+			RetryAfter: retryAfter,
+			Message:    fmt.Sprintf("telegram api error %d: %s", result.ErrorCode, result.Description),
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{Code: resp.StatusCode, RetryAfter: retryAfter}
 	}
 
-	return "ok", nil
+	return nil
+}
+
+// telegramText renders the message fields with MarkdownV2 escaping
+func telegramText(message Message) string {
+	lines := make([]string, 0, 7)
+	for _, field := range messageFields(message) {
+		lines = append(lines, fmt.Sprintf("*%s:* %s", telegramEscaper.Replace(field.Name), telegramEscaper.Replace(field.Value)))
+	}
+
+	return messageIcon(message) + strings.Join(lines, "\n")
+}
+
+// redactToken hides the bot token that is part of the request URL in transport errors
+func redactToken(err error, token string) error {
+	var urlErr *url.Error
+	if token == "" || !errors.As(err, &urlErr) {
+		return err
+	}
+	urlErr.URL = strings.ReplaceAll(urlErr.URL, token, "<token>")
+	return urlErr
 }
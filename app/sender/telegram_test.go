@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	config "micro-pinger/v2/app/service"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -88,7 +91,7 @@ func TestTelegram_Send(t *testing.T) {
 		}
 
 		// Compare the received message with the expected message
-		expectedMessage := getTextMessage(message)
+		expectedMessage := telegramText(message)
 		if receivedMessage.Text != expectedMessage {
 			t.Errorf("Expected message: %s, got: %s", expectedMessage, receivedMessage.Text)
 		}
@@ -153,3 +156,59 @@ func TestTelegram_SendError(t *testing.T) {
 		t.Errorf("Expected an error, got nil")
 	}
 }
+
+func TestTelegram_SendBotAPI(t *testing.T) {
+	var received TelegramMessage
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/bot123:ABC/sendMessage", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte(`{"ok": true, "result": {"message_id": 1}}`))
+	}))
+	defer mockServer.Close()
+
+	message := Message{
+		Status:      "[my_service] Service unreachable",
+		ServiceName: "my_service",
+		Response:    Response{Text: "Unexpected response status", Code: 502},
+		Alert:       config.Alert{Type: "telegram", BotToken: "123:ABC", To: "-100200", ThreadID: 7, APIURL: mockServer.URL},
+	}
+	require.NoError(t, NewTelegram(message).Send())
+
+	assert.Equal(t, "-100200", received.ChatID)
+	assert.Equal(t, 7, received.ThreadID)
+	assert.Equal(t, "MarkdownV2", received.ParseMode)
+	assert.Contains(t, received.Text, `*Service:* my\_service`)
+	assert.Contains(t, received.Text, `*Status:* \[my\_service\] Service unreachable`)
+
+	message.Alert.ChatID = "@alerts"
+	require.NoError(t, NewTelegram(message).Send())
+	assert.Equal(t, "@alerts", received.ChatID)
+}
+
+func TestTelegram_SendAPIError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok": false, "error_code": 400, "description": "Bad Request: chat not found"}`))
+	}))
+	defer mockServer.Close()
+
+	alert := config.Alert{BotToken: "123:ABC", ChatID: "42", APIURL: mockServer.URL}
+	err := NewTelegram(Message{Alert: alert}).Send()
+	assert.EqualError(t, err, "telegram api error 400: Bad Request: chat not found")
+
+	err = NewTelegram(Message{Alert: config.Alert{BotToken: "123:ABC"}}).Send()
+	assert.EqualError(t, err, "telegram chat id is not set")
+
+	err = NewTelegram(Message{}).Send()
+	assert.EqualError(t, err, "telegram bot token is not set")
+
+	// the token is part of the URL and must not leak into logs
+	err = NewTelegram(Message{Alert: config.Alert{BotToken: "123:SECRET", ChatID: "42", APIURL: "http://127.0.0.1:1"}}).Send()
+	require.Error(t, err)
+	assert.False(t, strings.Contains(err.Error(), "SECRET"))
+}
+
+func TestTelegramText_Escaping(t *testing.T) {
+	message := Message{ServiceName: "api*v2", Status: "OK", Url: "https://example.com/health?x=1", Event: EVENT_UP}
+	assert.Equal(t, "✅ *Service:* api\\*v2\n*Status:* OK\n*Datetime:* \n*URL:* https://example\\.com/health?x\\=1", telegramText(message))
+}
@@ -0,0 +1,71 @@
+package sender
+
+import (
+	"bytes"
+	"log"
+	config "micro-pinger/v2/app/service"
+	"text/template"
+	"time"
+)
+
+// TemplateData is the model the alert templates are rendered against
+type TemplateData struct {
+	Service       string            // service name
+	URL           string            // checked URL or address
+	Status        string            // alert headline, e.g. "[api] Service unreachable"
+	Event         Event             // down, up, degraded, cert-expiring or cert-invalid
+	PreviousState string            // up or down as sent with the previous alert, up at first
+	CurrentState  string            // up or down after the check
+	Datetime      string            // time of the check, "2006-01-02 15:04:05"
+	Code          int               // response status code, 0 without a response
+	Reason        string            // why the check failed, empty on recovery
+	Error         string            // error of the check, empty if none
+	Latency       time.Duration     // check duration, rounded to milliseconds
+	Failures      int               // consecutive failed checks
+	Duration      time.Duration     // outage duration, rounded to seconds
+	Labels        map[string]string // labels of the service
+}
+
+func newTemplateData(message Message) TemplateData {
+	data := TemplateData{
+		Service:       message.ServiceName,
+		URL:           message.Url,
+		Status:        message.Status,
+		Event:         messageEvent(message),
+		PreviousState: message.PreviousState,
+		CurrentState:  message.CurrentState,
+		Datetime:      message.Datetime,
+		Code:          message.Response.Code,
+		Reason:        message.Response.Text,
+		Latency:       message.Response.Latency.Round(time.Millisecond),
+		Failures:      message.Failures,
+		Duration:      message.Duration.Round(time.Second),
+		Labels:        message.Labels,
+	}
+	if message.Response.Err != nil {
+		data.Error = message.Response.Err.Error()
+	}
+	return data
+}
+
+// customText renders the alert template of the message event, it returns an empty string
+// when the alert has no template, so the sender falls back to its own layout
+func customText(message Message) string {
+	text, name := message.Alert.Template.Down, "down"
+	if !messageEvent(message).Failure() {
+		text, name = message.Alert.Template.Up, "up"
+	}
+	if text == "" {
+		return ""
+	}
+
+	tmpl, err := template.New(name).Funcs(config.TemplateFuncs).Parse(text)
+	if err == nil {
+		var buf bytes.Buffer
+		if err = tmpl.Execute(&buf, newTemplateData(message)); err == nil {
+			return buf.String()
+		}
+	}
+	log.Printf("[WARN] [%s] failed to render %s template of alert %s, %v", message.ServiceName, name, message.Alert.Name, err)
+	return ""
+}
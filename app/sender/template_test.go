@@ -0,0 +1,62 @@
+package sender
+
+import (
+	"errors"
+	config "micro-pinger/v2/app/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomText(t *testing.T) {
+	message := Message{
+		Status:      "[payments] Service unreachable",
+		Url:         "https://pay.example.com",
+		ServiceName: "payments",
+		Response:    Response{Text: "Unexpected response status", Code: 502, Err: errors.New("EOF"), Latency: 1234567 * time.Microsecond},
+		Failures:    3,
+		Duration:    90*time.Second + 300*time.Millisecond,
+		Labels:      map[string]string{"team": "billing"},
+		Alert: config.Alert{Template: config.Template{
+			Down: "{{.Service}} ({{.Labels.team}}) {{.Event}}: {{.Code}} {{.Reason}}, {{.Error}}, {{.Latency}}, {{.Failures}} failures for {{.Duration}}",
+			Up:   "{{.Service}} is back after {{.Duration}}",
+		}},
+	}
+
+	assert.Equal(t, "payments (billing) down: 502 Unexpected response status, EOF, 1.235s, 3 failures for 1m30s", customText(message))
+	assert.Equal(t, customText(message), getTextMessage(message))
+
+	message.Event = EVENT_UP
+	message.Response = Response{Code: 200}
+	assert.Equal(t, "payments is back after 1m30s", customText(message))
+
+	message.Alert.Template.Up = ""
+	assert.Empty(t, customText(message))
+	assert.Contains(t, getTextMessage(message), "*Service:* payments")
+
+	// a broken template falls back to the default layout
+	message.Alert.Template.Up = "{{.Missing}}"
+	assert.Empty(t, customText(message))
+}
+
+func TestCustomTextSenders(t *testing.T) {
+	message := Message{
+		Status:      "[payments] Service unreachable",
+		ServiceName: "payments",
+		Response:    Response{Text: "Unexpected response status", Code: 502},
+		Alert:       config.Alert{Template: config.Template{Down: "Payments are unavailable, the team is on it"}},
+	}
+
+	slackMessage := (&Slack{Message: message}).build()
+	assert.Equal(t, "Payments are unavailable, the team is on it", slackMessage.Text)
+	assert.Equal(t, "Payments are unavailable, the team is on it", slackMessage.Attachments[0].Blocks[1].Text.Text)
+
+	embed := (&Discord{Message: message}).build().Embeds[0]
+	assert.Equal(t, "Payments are unavailable, the team is on it", embed.Description)
+
+	card := (&Teams{Message: message}).build().Attachments[0].Content
+	assert.Equal(t, TeamsTextBlock{Type: "TextBlock", Text: "Payments are unavailable, the team is on it", Wrap: true}, card.Body[1])
+
+	assert.Equal(t, "Payments are unavailable, the team is on it", (&Opsgenie{Message: message}).build().Description)
+}
@@ -0,0 +1,62 @@
+package sender
+
+import (
+	"bytes"
+	"fmt"
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"strings"
+	"text/template"
+)
+
+// DEFAULT_WEBHOOK_PAYLOAD is used when the alert has no payload template
+const DEFAULT_WEBHOOK_PAYLOAD = `{"service": {{json .ServiceName}}, "event": {{json .Event}}, "status": {{json .Status}}, ` +
+	`"datetime": {{json .Datetime}}, "url": {{json .Url}}, "code": {{.Response.Code}}, "reason": {{json .Response.Text}}, ` +
+	`"error": {{json .Response.Err}}, "failures": {{.Failures}}, "duration": {{json .Duration.Seconds}}}`
+
+type Webhook struct {
+	Message Message
+}
+
+func NewWebhook(message Message) Sender {
+	return &Webhook{Message: message}
+}
+
+func (wh *Webhook) Send() error {
+	alert := wh.Message.Alert
+	payload := alert.Payload
+	if payload == "" {
+		payload = DEFAULT_WEBHOOK_PAYLOAD
+	}
+
+	tmpl, err := template.New("payload").Funcs(config.TemplateFuncs).Parse(payload)
+	if err != nil {
+		return fmt.Errorf("invalid webhook payload template: %w", err)
+	}
+	message := wh.Message
+	message.Event = messageEvent(message)
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, message); err != nil {
+		return fmt.Errorf("failed to render webhook payload: %w", err)
+	}
+
+	method := strings.ToUpper(alert.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(method, wh.Message.Webhook, &body)
+	if err != nil {
+		return err
+	}
+
+	contentType := alert.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	for _, header := range alert.Headers {
+		req.Header.Set(header.Name, header.Value)
+	}
+
+	return doRequest(req)
+}
@@ -0,0 +1,86 @@
+package sender
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	config "micro-pinger/v2/app/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_SendDefaultPayload(t *testing.T) {
+	var received map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer mockServer.Close()
+
+	message := Message{
+		Status:      `[Test "Service"] Service unreachable`,
+		Webhook:     mockServer.URL,
+		Datetime:    "2024-02-28T12:34:56",
+		Url:         "https://example.com",
+		ServiceName: `Test "Service"`,
+		Response:    Response{Text: "Error making HTTP request", Err: errors.New("connection refused"), Code: 500},
+		Alert:       config.Alert{Type: "webhook"},
+	}
+
+	webhookSender, err := NewSender("webhook", message)
+	require.NoError(t, err)
+	require.NoError(t, webhookSender.Send())
+
+	assert.Equal(t, `Test "Service"`, received["service"])
+	assert.Equal(t, `[Test "Service"] Service unreachable`, received["status"])
+	assert.Equal(t, 500.0, received["code"])
+	assert.Equal(t, "Error making HTTP request", received["reason"])
+	assert.Equal(t, "connection refused", received["error"])
+}
+
+func TestWebhook_SendTemplate(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "text/plain", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "TestService is down: Unexpected response status (502)", string(body))
+	}))
+	defer mockServer.Close()
+
+	message := Message{
+		Webhook:     mockServer.URL,
+		ServiceName: "TestService",
+		Response:    Response{Text: "Unexpected response status", Code: 502},
+		Alert: config.Alert{
+			Type:        "webhook",
+			Method:      "put",
+			ContentType: "text/plain",
+			Headers:     []config.Header{{Name: "Authorization", Value: "Bearer token"}},
+			Payload:     "{{.ServiceName}} is down: {{.Response.Text}} ({{.Response.Code}})",
+		},
+	}
+	require.NoError(t, NewWebhook(message).Send())
+}
+
+func TestWebhook_SendErrors(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer mockServer.Close()
+
+	err := NewWebhook(Message{Webhook: mockServer.URL}).Send()
+	assert.EqualError(t, err, "unexpected status code: 500")
+
+	err = NewWebhook(Message{Webhook: mockServer.URL, Alert: config.Alert{Payload: "{{.Missing"}}).Send()
+	assert.ErrorContains(t, err, "invalid webhook payload template")
+
+	err = NewWebhook(Message{Webhook: mockServer.URL, Alert: config.Alert{Payload: "{{.Missing}}"}}).Send()
+	assert.ErrorContains(t, err, "failed to render webhook payload")
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"log"
 	"micro-pinger/v2/app/handler"
+	"micro-pinger/v2/app/metrics"
+	"micro-pinger/v2/app/scheduler"
 	config "micro-pinger/v2/app/service"
+	"micro-pinger/v2/app/store"
 	"net/http"
 	"time"
 
@@ -17,6 +20,9 @@ import (
 	"github.com/pkg/errors"
 )
 
+// DELIVERY_SHUTDOWN_TIMEOUT limits how long the alerts being sent are waited for on shutdown
+const DELIVERY_SHUTDOWN_TIMEOUT = 15 * time.Second
+
 type Server struct {
 	Listen         string
 	PinSize        int
@@ -26,6 +32,8 @@ type Server struct {
 	Secret         string
 	Version        string
 	Config         config.Config
+	Store          store.Store
+	Delivery       *handler.Delivery // shared by the scheduler and the API, each handler gets its own if nil
 }
 
 func (s Server) Run(ctx context.Context) error {
@@ -40,6 +48,8 @@ func (s Server) Run(ctx context.Context) error {
 		IdleTimeout:       30 * time.Second,
 	}
 
+	go scheduler.NewScheduler(s.Config.Service, s.newHandler()).Run(ctx)
+
 	go func() {
 		<-ctx.Done()
 		if httpServer != nil {
@@ -51,6 +61,7 @@ func (s Server) Run(ctx context.Context) error {
 
 	err := httpServer.ListenAndServe()
 	log.Printf("[WARN] http server terminated, %s", err)
+	s.stopDelivery()
 
 	if err != http.ErrServerClosed {
 		return errors.Wrap(err, "server failed")
@@ -58,6 +69,18 @@ func (s Server) Run(ctx context.Context) error {
 	return err
 }
 
+// stopDelivery finishes the alerts being sent and dead-letters the pending ones before the process exits
+func (s Server) stopDelivery() {
+	if s.Delivery == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DELIVERY_SHUTDOWN_TIMEOUT)
+	defer cancel()
+	if err := s.Delivery.Shutdown(ctx); err != nil {
+		log.Printf("[WARN] alerts are still being sent on shutdown, %v", err)
+	}
+}
+
 func (s Server) routes() chi.Router {
 	router := chi.NewRouter()
 	router.Use(middleware.RequestID, middleware.RealIP)
@@ -66,18 +89,23 @@ func (s Server) routes() chi.Router {
 	router.Use(tollbooth_chi.LimitHandler(tollbooth.NewLimiter(10, nil)))
 	router.Use(middleware.Logger)
 
-	client := &http.Client{}
-
-	handler := handler.NewHandler(s.Config.Service, client)
+	handler := s.newHandler()
 
 	router.Route(
 		"/api/v1", func(r chi.Router) {
 			r.Use(rest.Authentication("Api-Key", s.Secret))
 			//	r.Use(ReloadConfigMiddleware(s.Config))
 			r.Get("/check", handler.Check)
+			r.Get("/check/{name}", handler.CheckOne)
+			r.Get("/services/{name}/history", handler.History)
+			r.Get("/services/{name}/uptime", handler.Uptime)
+			r.Post("/services/{name}/ack", handler.Acknowledge)
+			r.Post("/alerts/dead-letter/replay", handler.ReplayDeadLetters)
 		},
 	)
 
+	router.Handle("/metrics", metrics.Handler())
+
 	router.Get(
 		"/robots.txt", func(w http.ResponseWriter, r *http.Request) {
 			render.PlainText(w, r, "User-agent: *\nDisallow: /\n")
@@ -86,3 +114,13 @@ func (s Server) routes() chi.Router {
 
 	return router
 }
+
+func (s Server) newHandler() handler.Handler {
+	client := &http.Client{Timeout: handler.DEFAULT_TIMEOUT}
+	h := handler.NewHandler(s.Config.Service, client)
+	h.Store = s.Store
+	if s.Delivery != nil {
+		h.Delivery = s.Delivery
+	}
+	return h
+}
@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"io"
+	config "micro-pinger/v2/app/service"
+	"micro-pinger/v2/app/store"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -71,3 +73,30 @@ func TestRest_RobotsCheck(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "User-agent: *\nDisallow: /\n", string(body))
 }
+
+func TestServer_RoutesHistory(t *testing.T) {
+	testServer := Server{Store: store.NewMemory()}
+	testServer.Config.Service = []config.Service{{Name: "google"}}
+	router := testServer.routes()
+
+	for _, url := range []string{"/api/v1/services/google/history", "/api/v1/services/google/uptime?window=7d"} {
+		req, err := http.NewRequest("GET", url, nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, url)
+	}
+}
+
+func TestRest_Metrics(t *testing.T) {
+	srv := Server{Listen: "localhost:54009", Version: "v1", Secret: "12345"}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "go_goroutines")
+}
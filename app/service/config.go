@@ -1,7 +1,14 @@
 package service
 
 import (
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"micro-pinger/v2/app/jsonpath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -11,15 +18,31 @@ type Config struct {
 }
 
 type Service struct {
-	Name     string   `yaml:"name"`
-	URL      string   `yaml:"url"`
-	Method   string   `yaml:"method"`
-	Type     string   `yaml:"type"`
-	Body     string   `yaml:"body"`
-	Interval string   `yaml:"interval"`
-	Headers  []Header `yaml:"headers"`
-	Response Response `yaml:"response"`
-	Alerts   []Alert  `yaml:"alerts"`
+	Name            string            `yaml:"name"`
+	URL             string            `yaml:"url"`
+	Method          string            `yaml:"method"`
+	Type            string            `yaml:"type"`
+	Body            string            `yaml:"body"`
+	Interval        string            `yaml:"interval"`
+	Timeout         string            `yaml:"timeout"`
+	FollowRedirects *bool             `yaml:"follow-redirects"`
+	MaxRedirects    int               `yaml:"max-redirects"`
+	Headers         []Header          `yaml:"headers"`
+	Response        Response          `yaml:"response"`
+	DNS             DNS               `yaml:"dns"`
+	TLS             TLS               `yaml:"tls"`
+	Alerts          []Alert           `yaml:"alerts"`
+	Labels          map[string]string `yaml:"labels"` // passed to alert templates
+	Escalation      []EscalationStep  `yaml:"escalation"`
+}
+
+// EscalationStep notifies alerts of the service once the outage lasts for Failures checks and After time.
+// Alerts listed in any step are sent by the escalation only, their own failure threshold is ignored.
+type EscalationStep struct {
+	Failures int      `yaml:"failures"` // consecutive failed checks before the step starts
+	After    string   `yaml:"after"`    // outage duration before the step starts
+	Alerts   []string `yaml:"alerts"`   // names of the service alerts to notify
+	Repeat   string   `yaml:"repeat"`   // re-notify interval until the outage is acknowledged, once if empty
 }
 
 type Header struct {
@@ -28,19 +51,93 @@ type Header struct {
 }
 
 type Response struct {
-	Status  int    `yaml:"status"`
-	Body    string `yaml:"body"`
-	Compare string `yaml:"compare"`
+	Status     int              `yaml:"status"`
+	Body       string           `yaml:"body"`
+	Compare    string           `yaml:"compare"`
+	JSON       []string         `yaml:"json"`
+	MaxLatency string           `yaml:"max-latency"`
+	Headers    []ResponseHeader `yaml:"headers"`
+	Regexp     *regexp.Regexp   `yaml:"-"` // compiled Body for regex and not-regex compare
+}
+
+type ResponseHeader struct {
+	Name    string         `yaml:"name"`
+	Value   string         `yaml:"value"`
+	Compare string         `yaml:"compare"` // equals (default), contains, regex, present, absent
+	Regexp  *regexp.Regexp `yaml:"-"`
+}
+
+type DNS struct {
+	Resolver string   `yaml:"resolver"`
+	Record   string   `yaml:"record"` // A (default), AAAA, CNAME, MX, TXT or NS
+	Values   []string `yaml:"values"`
+}
+
+type TLS struct {
+	ExpiryDays         int    `yaml:"expiry-days"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify"`
+	CA                 string `yaml:"ca"`   // path to a PEM CA bundle
+	Cert               string `yaml:"cert"` // path to a PEM client certificate for mTLS
+	Key                string `yaml:"key"`  // path to a PEM client key for mTLS
 }
 
 type Alert struct {
-	Name          string `yaml:"name"`
-	Type          string `yaml:"type"`
-	Webhook       string `yaml:"webhook"`
-	To            string `yaml:"to"`
-	Failure       int    `yaml:"failure"`
-	Success       int    `yaml:"success"`
-	SendOnResolve bool   `yaml:"send-on-resolve"`
+	Name          string      `yaml:"name"`
+	Type          string      `yaml:"type"`
+	Webhook       string      `yaml:"webhook"`
+	To            string      `yaml:"to"`
+	Failure       int         `yaml:"failure"`
+	Success       int         `yaml:"success"`
+	SendOnResolve bool        `yaml:"send-on-resolve"`
+	SMTP          SMTP        `yaml:"smtp"`
+	Method        string      `yaml:"method"`       // webhook request method, POST by default
+	Headers       []Header    `yaml:"headers"`      // webhook request headers
+	ContentType   string      `yaml:"content-type"` // webhook content type, application/json by default
+	Payload       string      `yaml:"payload"`      // webhook body, Go text/template over sender.Message
+	RoutingKey    string      `yaml:"routing-key"`  // pagerduty integration key
+	Severity      string      `yaml:"severity"`     // pagerduty event severity, critical by default
+	APIURL        string      `yaml:"api-url"`      // overrides the default API endpoint of the sender
+	APIKey        string      `yaml:"api-key"`      // opsgenie API key
+	Region        string      `yaml:"region"`       // opsgenie API region, us (default) or eu
+	Priority      string      `yaml:"priority"`     // opsgenie priority P1-P5
+	Tags          []string    `yaml:"tags"`         // opsgenie tags
+	Responders    []Responder `yaml:"responders"`   // opsgenie responders
+	BotToken      string      `yaml:"bot-token"`    // telegram or slack bot token
+	ChatID        string      `yaml:"chat-id"`      // telegram chat ID or @channel, to by default
+	ThreadID      int         `yaml:"thread-id"`    // telegram forum topic
+	Template      Template    `yaml:"template"`     // custom message text
+	Retry         Retry       `yaml:"retry"`        // redelivery of failed alerts
+}
+
+// Retry configures redelivery of alerts rejected with a rate limit, a server error or a network error
+type Retry struct {
+	Attempts   int    `yaml:"attempts"`    // deliveries before the alert is dead-lettered, 3 by default
+	Backoff    string `yaml:"backoff"`     // delay before the first retry, doubled after each one, 1s by default
+	MaxBackoff string `yaml:"max-backoff"` // longest delay between retries, also caps Retry-After, 1m by default
+}
+
+// Template holds Go text/templates of the alert text, rendered over sender.TemplateData
+type Template struct {
+	Down     string `yaml:"down"`      // failure message
+	Up       string `yaml:"up"`        // recovery message
+	DownFile string `yaml:"down-file"` // path to the failure template, read on load
+	UpFile   string `yaml:"up-file"`   // path to the recovery template, read on load
+}
+
+type Responder struct {
+	Type     string `yaml:"type"` // team, user, escalation or schedule
+	ID       string `yaml:"id"`
+	Name     string `yaml:"name"`
+	Username string `yaml:"username"`
+}
+
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+	TLS      string `yaml:"tls"` // starttls (default), tls or none
 }
 
 func LoadConfig(filename string) (Config, error) {
@@ -55,5 +152,167 @@ func LoadConfig(filename string) (Config, error) {
 		return Config{}, err
 	}
 
+	err = config.validate()
+	if err != nil {
+		return Config{}, err
+	}
+
 	return config, nil
 }
+
+// validate rejects invalid patterns and assertions, so they are not discovered on every check
+func (c *Config) validate() error {
+	for i := range c.Service {
+		service := &c.Service[i]
+
+		if service.Response.Compare == "regex" || service.Response.Compare == "not-regex" {
+			pattern, err := regexp.Compile(service.Response.Body)
+			if err != nil {
+				return fmt.Errorf("service %s: invalid response body pattern: %w", service.Name, err)
+			}
+			service.Response.Regexp = pattern
+		}
+
+		if service.Timeout != "" {
+			if d, err := time.ParseDuration(service.Timeout); err != nil || d <= 0 {
+				return fmt.Errorf("service %s: invalid timeout %q", service.Name, service.Timeout)
+			}
+		}
+
+		switch strings.ToUpper(service.DNS.Record) {
+		case "", "A", "AAAA", "CNAME", "MX", "TXT", "NS":
+		default:
+			return fmt.Errorf("service %s: unsupported dns record %q", service.Name, service.DNS.Record)
+		}
+
+		if service.Response.MaxLatency != "" {
+			if _, err := time.ParseDuration(service.Response.MaxLatency); err != nil {
+				return fmt.Errorf("service %s: invalid max-latency: %w", service.Name, err)
+			}
+		}
+
+		for j := range service.Response.Headers {
+			header := &service.Response.Headers[j]
+			switch header.Compare {
+			case "", "equals", "contains", "present", "absent":
+			case "regex":
+				pattern, err := regexp.Compile(header.Value)
+				if err != nil {
+					return fmt.Errorf("service %s: invalid pattern for header %s: %w", service.Name, header.Name, err)
+				}
+				header.Regexp = pattern
+			default:
+				return fmt.Errorf("service %s: unsupported compare %q for header %s", service.Name, header.Compare, header.Name)
+			}
+		}
+
+		for _, expr := range service.Response.JSON {
+			if _, err := jsonpath.Parse(expr); err != nil {
+				return fmt.Errorf("service %s: %w", service.Name, err)
+			}
+		}
+
+		if err := service.validateEscalation(); err != nil {
+			return fmt.Errorf("service %s: %w", service.Name, err)
+		}
+
+		for j := range service.Alerts {
+			alert := &service.Alerts[j]
+			if err := alert.Template.load(); err != nil {
+				return fmt.Errorf("service %s: alert %s: %w", service.Name, alert.Name, err)
+			}
+			if err := alert.parseTemplates(); err != nil {
+				return fmt.Errorf("service %s: alert %s: %w", service.Name, alert.Name, err)
+			}
+			for _, value := range []string{alert.Retry.Backoff, alert.Retry.MaxBackoff} {
+				if value == "" {
+					continue
+				}
+				if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+					return fmt.Errorf("service %s: alert %s: invalid retry backoff %q", service.Name, alert.Name, value)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s Service) validateEscalation() error {
+	names := make(map[string]bool, len(s.Alerts))
+	for _, alert := range s.Alerts {
+		names[alert.Name] = true
+	}
+
+	for i, step := range s.Escalation {
+		if step.Failures <= 0 && step.After == "" {
+			return fmt.Errorf("escalation step %d: failures or after is required", i+1)
+		}
+		for _, value := range []string{step.After, step.Repeat} {
+			if value == "" {
+				continue
+			}
+			if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+				return fmt.Errorf("escalation step %d: invalid duration %q", i+1, value)
+			}
+		}
+		if len(step.Alerts) == 0 {
+			return fmt.Errorf("escalation step %d: no alerts", i+1)
+		}
+		for _, name := range step.Alerts {
+			if !names[name] {
+				return fmt.Errorf("escalation step %d: unknown alert %s", i+1, name)
+			}
+		}
+	}
+	return nil
+}
+
+// load reads the template files into Down and Up
+// TemplateFuncs are the functions available in alert templates and webhook payloads
+var TemplateFuncs = template.FuncMap{
+	// json renders any value as a JSON literal, errors as their text
+	"json": func(v interface{}) (string, error) {
+		if err, ok := v.(error); ok && err != nil {
+			v = err.Error()
+		}
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// parseTemplates checks the message templates and the webhook payload, so a broken one fails the load
+// instead of the alert
+func (a Alert) parseTemplates() error {
+	templates := []struct{ name, text string }{{"down", a.Template.Down}, {"up", a.Template.Up}}
+	if a.Type == "webhook" {
+		templates = append(templates, struct{ name, text string }{"payload", a.Payload})
+	}
+	for _, t := range templates {
+		if _, err := template.New(t.name).Funcs(TemplateFuncs).Parse(t.text); err != nil {
+			return fmt.Errorf("invalid %s template: %w", t.name, err)
+		}
+	}
+	return nil
+}
+
+func (t *Template) load() error {
+	files := []struct {
+		path string
+		text *string
+	}{{t.DownFile, &t.Down}, {t.UpFile, &t.Up}}
+
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		if *file.text != "" {
+			return fmt.Errorf("both template and template file %s are set", file.path)
+		}
+		data, err := ioutil.ReadFile(file.path)
+		if err != nil {
+			return fmt.Errorf("failed to read template: %w", err)
+		}
+		*file.text = string(data)
+	}
+	return nil
+}
@@ -66,3 +66,294 @@ func TestBadConfig(t *testing.T) {
 
 	assert.Error(t, err, "Expected an error loading bad config file")
 }
+
+func TestLoadConfigRegex(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "test-config-*.yaml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	yamlContent := []byte(`
+services:
+  - name: example
+    url: https://example.com/version
+    response:
+      status: 200
+      body: "^v\\d+\\.\\d+\\.\\d+$"
+      compare: regex
+`)
+	_, err = tempFile.Write(yamlContent)
+	assert.NoError(t, err)
+
+	config, err := LoadConfig(tempFile.Name())
+	assert.NoError(t, err)
+	assert.NotNil(t, config.Service[0].Response.Regexp)
+	assert.True(t, config.Service[0].Response.Regexp.MatchString("v1.2.3"))
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "InvalidRegex",
+			content: `
+services:
+  - name: example
+    response:
+      body: "v(1"
+      compare: not-regex
+`,
+		},
+		{
+			name: "InvalidTimeout",
+			content: `
+services:
+  - name: example
+    timeout: 3 seconds
+`,
+		},
+		{
+			name: "UnsupportedDNSRecord",
+			content: `
+services:
+  - name: example
+    type: dns
+    url: example.com
+    dns:
+      record: SRV
+`,
+		},
+		{
+			name: "InvalidJSONAssertion",
+			content: `
+services:
+  - name: example
+    response:
+      json:
+        - status == "UP"
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tempFile, err := ioutil.TempFile("", "test-config-*.yaml")
+			assert.NoError(t, err)
+			defer os.Remove(tempFile.Name())
+
+			_, err = tempFile.Write([]byte(tc.content))
+			assert.NoError(t, err)
+
+			_, err = LoadConfig(tempFile.Name())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadConfigInvalidMaxLatency(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "test-config-*.yaml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(`
+services:
+  - name: example
+    response:
+      max-latency: fast
+`))
+	assert.NoError(t, err)
+
+	_, err = LoadConfig(tempFile.Name())
+	assert.Error(t, err)
+}
+
+func TestLoadConfigResponseHeaders(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "test-config-*.yaml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(`
+services:
+  - name: example
+    response:
+      headers:
+        - name: Cache-Control
+          compare: present
+        - name: Content-Type
+          value: "^application/json"
+          compare: regex
+`))
+	assert.NoError(t, err)
+
+	config, err := LoadConfig(tempFile.Name())
+	assert.NoError(t, err)
+	assert.Nil(t, config.Service[0].Response.Headers[0].Regexp)
+	assert.NotNil(t, config.Service[0].Response.Headers[1].Regexp)
+
+	_, err = tempFile.Seek(0, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, tempFile.Truncate(0))
+	_, err = tempFile.Write([]byte(`
+services:
+  - name: example
+    response:
+      headers:
+        - name: Content-Type
+          compare: similar
+`))
+	assert.NoError(t, err)
+
+	_, err = LoadConfig(tempFile.Name())
+	assert.Error(t, err)
+}
+
+func TestLoadConfigAlertTemplate(t *testing.T) {
+	templateFile, err := ioutil.TempFile("", "test-template-*.tmpl")
+	assert.NoError(t, err)
+	defer os.Remove(templateFile.Name())
+	_, err = templateFile.Write([]byte("{{.Service}} is down for {{.Duration}}"))
+	assert.NoError(t, err)
+
+	tempFile, err := ioutil.TempFile("", "test-config-*.yaml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(`
+services:
+  - name: example
+    labels:
+      team: payments
+    alerts:
+      - name: manager
+        type: slack
+        template:
+          down-file: ` + templateFile.Name() + `
+          up: "{{.Service}} is back"
+`))
+	assert.NoError(t, err)
+
+	config, err := LoadConfig(tempFile.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments"}, config.Service[0].Labels)
+	assert.Equal(t, "{{.Service}} is down for {{.Duration}}", config.Service[0].Alerts[0].Template.Down)
+	assert.Equal(t, "{{.Service}} is back", config.Service[0].Alerts[0].Template.Up)
+
+	_, err = tempFile.Seek(0, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, tempFile.Truncate(0))
+	_, err = tempFile.Write([]byte(`
+services:
+  - name: example
+    alerts:
+      - name: manager
+        type: slack
+        template:
+          up-file: /nonexistent/up.tmpl
+`))
+	assert.NoError(t, err)
+
+	_, err = LoadConfig(tempFile.Name())
+	assert.ErrorContains(t, err, "service example: alert manager: failed to read template")
+}
+
+func TestLoadConfigInvalidTemplates(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "test-config-*.yaml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	write := func(alerts string) {
+		_, err := tempFile.Seek(0, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, tempFile.Truncate(0))
+		_, err = tempFile.Write([]byte(`
+services:
+  - name: payments
+    alerts:
+` + alerts))
+		assert.NoError(t, err)
+	}
+
+	write(`
+      - name: manager
+        type: slack
+        template:
+          down: "{{json .Service}}"
+          up: "{{.Service}"
+`)
+	_, err = LoadConfig(tempFile.Name())
+	assert.EqualError(t, err, "service payments: alert manager: invalid up template: template: up:1: bad character U+007D '}'")
+
+	write(`
+      - name: incidents
+        type: webhook
+        payload: '{"service": {{json .ServiceName}'
+`)
+	_, err = LoadConfig(tempFile.Name())
+	assert.EqualError(t, err, "service payments: alert incidents: invalid payload template: template: payload:1: bad character U+007D '}'")
+
+	write(`
+      - name: incidents
+        type: webhook
+        payload: '{"service": {{json .ServiceName}}}'
+`)
+	_, err = LoadConfig(tempFile.Name())
+	assert.NoError(t, err)
+}
+
+func TestLoadConfigEscalation(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "test-config-*.yaml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	write := func(escalation string) {
+		_, err := tempFile.Seek(0, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, tempFile.Truncate(0))
+		_, err = tempFile.Write([]byte(`
+services:
+  - name: example
+    alerts:
+      - name: devops
+        type: slack
+      - name: manager
+        type: email
+    escalation:
+` + escalation))
+		assert.NoError(t, err)
+	}
+
+	write(`
+      - failures: 3
+        alerts: [devops]
+        repeat: 15m
+      - after: 30m
+        alerts: [manager]
+`)
+	config, err := LoadConfig(tempFile.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, []EscalationStep{
+		{Failures: 3, Alerts: []string{"devops"}, Repeat: "15m"},
+		{After: "30m", Alerts: []string{"manager"}},
+	}, config.Service[0].Escalation)
+
+	write(`
+      - failures: 3
+        alerts: [oncall]
+`)
+	_, err = LoadConfig(tempFile.Name())
+	assert.EqualError(t, err, "service example: escalation step 1: unknown alert oncall")
+
+	write(`
+      - alerts: [devops]
+`)
+	_, err = LoadConfig(tempFile.Name())
+	assert.EqualError(t, err, "service example: escalation step 1: failures or after is required")
+
+	write(`
+      - after: soon
+        alerts: [devops]
+`)
+	_, err = LoadConfig(tempFile.Name())
+	assert.EqualError(t, err, `service example: escalation step 1: invalid duration "soon"`)
+}
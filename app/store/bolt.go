@@ -0,0 +1,133 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	stateBucket   = []byte("state")
+	historyBucket = []byte("history")
+)
+
+// Bolt keeps states and check results in a BoltDB file so they survive restarts.
+// Results are stored in a bucket per service keyed by the big-endian check time.
+type Bolt struct {
+	Retention time.Duration // results older than this are dropped
+
+	db *bolt.DB
+}
+
+func NewBolt(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(stateBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(historyBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create buckets: %w", err)
+	}
+	return &Bolt{Retention: DEFAULT_RETENTION, db: db}, nil
+}
+
+func (b *Bolt) Load() (map[string]State, error) {
+	states := make(map[string]State)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(stateBucket).ForEach(func(k, v []byte) error {
+			var state State
+			if err := json.Unmarshal(v, &state); err != nil {
+				return fmt.Errorf("failed to decode state %s: %w", k, err)
+			}
+			states[string(k)] = state
+			return nil
+		})
+	})
+	return states, err
+}
+
+func (b *Bolt) Save(key string, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(stateBucket)
+		if saved := bucket.Get([]byte(key)); saved != nil {
+			var savedState State
+			if err := json.Unmarshal(saved, &savedState); err == nil && state.Seq < savedState.Seq {
+				return nil
+			}
+		}
+		return bucket.Put([]byte(key), data)
+	})
+}
+
+func (b *Bolt) AddResult(service string, result Result) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(historyBucket).CreateBucketIfNotExists([]byte(service))
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(timeKey(result.Time), data); err != nil {
+			return err
+		}
+
+		cutoff := timeKey(time.Now().Add(-b.Retention))
+		c := bucket.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, cutoff) < 0; k, _ = c.Next() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *Bolt) Results(service string, since time.Time) ([]Result, error) {
+	var results []Result
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket).Bucket([]byte(service))
+		if bucket == nil {
+			return nil
+		}
+		c := bucket.Cursor()
+		for k, v := c.Seek(timeKey(since)); k != nil; k, v = c.Next() {
+			var result Result
+			if err := json.Unmarshal(v, &result); err != nil {
+				return fmt.Errorf("failed to decode result of %s: %w", service, err)
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	return results, err
+}
+
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	if t.Before(time.Unix(0, 0)) {
+		return key
+	}
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// State is the alert state of a service, keyed by "<service>_<alert>"
+type State struct {
+	Failures     int       `json:"failures"`
+	Successes    int       `json:"successes"`
+	LastStatus   string    `json:"last_status"`
+	LastAlert    time.Time `json:"last_alert"`
+	LastNotified string    `json:"last_notified,omitempty"` // state sent with the last alert
+	SlackThread  string    `json:"slack_thread,omitempty"`  // ts of the slack outage message
+	DownSince    time.Time `json:"down_since,omitempty"`    // first failed check of the current outage
+	Acknowledged time.Time `json:"acknowledged,omitempty"`  // the current outage was acknowledged, escalation is stopped
+	Seq          uint64    `json:"seq,omitempty"`           // order of the snapshot, an older one doesn't replace a saved newer one
+}
+
+// Result is a single check of a service
+type Result struct {
+	Time    time.Time     `json:"time"`
+	Success bool          `json:"success"`
+	Latency time.Duration `json:"-"` // written as latency_ms
+	Code    int           `json:"code"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// MarshalJSON writes Latency in milliseconds
+func (r Result) MarshalJSON() ([]byte, error) {
+	type result Result
+	return json.Marshal(struct {
+		result
+		LatencyMs float64 `json:"latency_ms"`
+	}{result(r), Milliseconds(r.Latency)})
+}
+
+func (r *Result) UnmarshalJSON(data []byte) error {
+	type result Result
+	aux := struct {
+		*result
+		LatencyMs float64 `json:"latency_ms"`
+	}{result: (*result)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.Latency = time.Duration(aux.LatencyMs * float64(time.Millisecond))
+	return nil
+}
+
+// Milliseconds converts a latency to fractional milliseconds as reported by the API
+func Milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+const (
+	DEFAULT_RETENTION      = 31 * 24 * time.Hour
+	DEFAULT_MEMORY_RESULTS = 10000
+)
+
+type Store interface {
+	Load() (map[string]State, error)
+	Save(key string, state State) error // ignores a state with a lower Seq than the saved one
+	AddResult(service string, result Result) error
+	Results(service string, since time.Time) ([]Result, error)
+	Close() error
+}
+
+type Memory struct {
+	Retention time.Duration // results older than this are dropped
+	Limit     int           // at most this many results are kept per service, the oldest are dropped
+
+	mu      sync.Mutex
+	states  map[string]State
+	results map[string][]Result
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		Retention: DEFAULT_RETENTION,
+		Limit:     DEFAULT_MEMORY_RESULTS,
+		states:    make(map[string]State),
+		results:   make(map[string][]Result),
+	}
+}
+
+func (m *Memory) Load() (map[string]State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	states := make(map[string]State, len(m.states))
+	for key, state := range m.states {
+		states[key] = state
+	}
+	return states, nil
+}
+
+func (m *Memory) Save(key string, state State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if saved, ok := m.states[key]; ok && state.Seq < saved.Seq {
+		return nil
+	}
+	m.states[key] = state
+	return nil
+}
+
+func (m *Memory) AddResult(service string, result Result) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	results := append(m.results[service], result)
+	cutoff := time.Now().Add(-m.Retention)
+	expired := 0
+	for expired < len(results) && results[expired].Time.Before(cutoff) {
+		expired++
+	}
+	if m.Limit > 0 && len(results)-expired > m.Limit {
+		expired = len(results) - m.Limit
+	}
+	m.results[service] = results[expired:]
+	return nil
+}
+
+func (m *Memory) Results(service string, since time.Time) ([]Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// results are kept in check order, skip the older ones without scanning them
+	all := m.results[service]
+	start := sort.Search(len(all), func(i int) bool { return !all[i].Time.Before(since) })
+	if start == len(all) {
+		return nil, nil
+	}
+	return append([]Result(nil), all[start:]...), nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T, st Store) {
+	states, err := st.Load()
+	require.NoError(t, err)
+	assert.Empty(t, states)
+
+	lastAlert := time.Date(2024, 2, 28, 12, 34, 56, 0, time.UTC)
+	require.NoError(t, st.Save("google_devops", State{Failures: 3, LastStatus: "down", LastAlert: lastAlert}))
+	require.NoError(t, st.Save("google_manager", State{Failures: 1, LastStatus: "down"}))
+	require.NoError(t, st.Save("google_manager", State{Failures: 2, LastStatus: "down"}))
+
+	states, err = st.Load()
+	require.NoError(t, err)
+	assert.Len(t, states, 2)
+	assert.Equal(t, 3, states["google_devops"].Failures)
+	assert.True(t, lastAlert.Equal(states["google_devops"].LastAlert))
+	assert.Equal(t, 2, states["google_manager"].Failures)
+
+	// a snapshot saved late doesn't replace a newer one
+	require.NoError(t, st.Save("google_support", State{Failures: 3, Seq: 8}))
+	require.NoError(t, st.Save("google_support", State{Failures: 2, Seq: 7}))
+	states, err = st.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 3, states["google_support"].Failures)
+}
+
+func testResults(t *testing.T, st Store) {
+	now := time.Now()
+	require.NoError(t, st.AddResult("google", Result{Time: now.Add(-40 * 24 * time.Hour), Success: true}))
+	require.NoError(t, st.AddResult("google", Result{Time: now.Add(-2 * time.Hour), Success: true, Latency: time.Second, Code: 200}))
+	require.NoError(t, st.AddResult("google", Result{Time: now.Add(-time.Hour), Success: false, Code: 502, Error: "Unexpected response status"}))
+	require.NoError(t, st.AddResult("facebook", Result{Time: now, Success: true, Code: 200}))
+
+	results, err := st.Results("google", now.Add(-50*24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, results, 2, "results older than retention are dropped")
+	assert.Equal(t, time.Second, results[0].Latency)
+	assert.Equal(t, "Unexpected response status", results[1].Error)
+
+	results, err = st.Results("google", now.Add(-90*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 502, results[0].Code)
+
+	results, err = st.Results("unknown", time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestMemory(t *testing.T) {
+	st := NewMemory()
+	testStore(t, st)
+	testResults(t, st)
+	assert.NoError(t, st.Close())
+}
+
+func TestMemoryLimit(t *testing.T) {
+	st := NewMemory()
+	st.Limit = 2
+	now := time.Now()
+	for i := 3; i > 0; i-- {
+		require.NoError(t, st.AddResult("google", Result{Time: now.Add(-time.Duration(i) * time.Minute), Code: i}))
+	}
+
+	results, err := st.Results("google", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, results, 2, "the oldest result is dropped")
+	assert.Equal(t, 2, results[0].Code)
+	assert.Equal(t, 1, results[1].Code)
+
+	results, err = st.Results("google", now)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestBolt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+
+	st, err := NewBolt(path)
+	require.NoError(t, err)
+	testStore(t, st)
+	testResults(t, st)
+	require.NoError(t, st.Close())
+
+	// reopened file keeps the states
+	st, err = NewBolt(path)
+	require.NoError(t, err)
+	defer st.Close()
+	states, err := st.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 3, states["google_devops"].Failures)
+	assert.Equal(t, "down", states["google_devops"].LastStatus)
+	results, err := st.Results("google", time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+}
+
+func TestBoltBadPath(t *testing.T) {
+	_, err := NewBolt(filepath.Join(t.TempDir(), "missing", "state.db"))
+	assert.Error(t, err)
+}